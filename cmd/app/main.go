@@ -7,8 +7,20 @@ package main
 //	@host		localhost:8080
 //	@BasePath	/api/v1
 
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				JWT токен доступа в формате "Bearer <token>"
+
+//	@securityDefinitions.apikey	ApiKeyAuth
+//	@in							header
+//	@name						Authorization
+//	@description				API ключ в формате "ApiKey <key>"
+
 import (
 	"os"
+	// База часовых поясов встроена в бинарник: параметр tz работает и в образе без tzdata
+	_ "time/tzdata"
 
 	"github.com/levinOo/go-crudl-task/internal/app"
 )
@@ -15,208 +15,325 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/subscriptions": {
+        "/admin/exchange-rates": {
             "get": {
-                "description": "Получить список всех подписок пользователя",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получить курсы валют, по которым пересчитывается стоимость подписок в отчетах",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
-                ],
-                "summary": "Получение списка подписок",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "UUID пользователя",
-                        "name": "user_id",
-                        "in": "query",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Получение курсов валют",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Subscription"
+                                "$ref": "#/definitions/domain.ExchangeRate"
                             }
                         }
                     },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Создать новую подписку",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Загрузить курсы валют из файла и заменить ими текущие курсы организации. Тело запроса - содержимое файла.\nПоддерживается XML в формате ЕЦБ (eurofxref, базовая валюта EUR) и CSV со строками вида currency,rate,\nгде rate - количество единиц валюты за единицу базовой валюты из параметра base",
                 "consumes": [
-                    "application/json"
+                    "text/xml",
+                    "text/plain"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "admin"
                 ],
-                "summary": "Создание подписки",
+                "summary": "Загрузка курсов валют",
                 "parameters": [
                     {
-                        "description": "Данные подписки",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.createSubInput"
-                        }
+                        "enum": [
+                            "ecb",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Формат файла: ecb или csv. По умолчанию определяется по Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "RUB",
+                        "description": "Базовая валюта курсов из CSV файла",
+                        "name": "base",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "ID подписки",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.ExchangeRate"
                             }
                         }
                     },
                     "400": {
-                        "description": "Неверное тело запроса",
+                        "description": "Неверный файл курсов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     }
                 }
             }
         },
-        "/subscriptions/total-cost": {
-            "get": {
-                "description": "Получить суммарную стоимость подписок за выбранный период с фильтрацией по user_id и названию подписки",
+        "/admin/subscriptions/purge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Окончательно удалить подписки, пролежавшие в корзине дольше срока хранения (параметр trash.retention)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "admin"
                 ],
-                "summary": "Подсчитать суммарную стоимость подписок",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "UUID пользователя",
-                        "name": "user_id",
-                        "in": "query",
-                        "required": true
+                "summary": "Очистка корзины подписок",
+                "responses": {
+                    "200": {
+                        "description": "Количество удаленных подписок",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer",
+                                "format": "int64"
+                            }
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Название подписки",
-                        "name": "service_name",
-                        "in": "query"
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Начальная дата (формат MM-YYYY)",
-                        "name": "start_date",
-                        "in": "query",
-                        "required": true
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
                     },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api-keys": {
+            "get": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Конечная дата (формат MM-YYYY)",
-                        "name": "end_date",
-                        "in": "query",
-                        "required": true
+                        "BearerAuth": []
                     }
                 ],
+                "description": "Получить API ключи текущего пользователя, включая отозванные. Сами ключи не возвращаются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "Список API ключей",
                 "responses": {
                     "200": {
-                        "description": "Суммарная стоимость",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "integer"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.APIKey"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Неверные параметры",
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     }
                 }
-            }
-        },
-        "/subscriptions/{id}": {
-            "get": {
-                "description": "Получить информацию о подписке по её ID",
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Создать API ключ для межсервисных вызовов от имени текущего пользователя.\nКлюч передается в заголовке \"Authorization: ApiKey \u003ckey\u003e\" и возвращается только в этом ответе",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "api-keys"
                 ],
-                "summary": "Получение подписки",
+                "summary": "Создание API ключа",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID подписки",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Данные API ключа",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createAPIKeyInput"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Созданный ключ",
                         "schema": {
-                            "$ref": "#/definitions/domain.Subscription"
+                            "$ref": "#/definitions/domain.IssuedAPIKey"
                         }
                     },
-                    "404": {
-                        "description": "Подписка не найдена",
+                    "400": {
+                        "description": "Неверное тело запроса",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api-keys/{id}": {
             "delete": {
-                "description": "Удалить подписку по ID",
-                "produces": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Отозвать API ключ. Отозванный ключ перестает действовать и не может быть восстановлен",
                 "tags": [
-                    "subscriptions"
+                    "api-keys"
                 ],
-                "summary": "Удаление подписки",
+                "summary": "Отзыв API ключа",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID подписки",
+                        "description": "ID API ключа",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -224,126 +341,2307 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "204": {
-                        "description": "Подписка успешно удалена"
+                        "description": "API ключ отозван"
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
                     },
                     "404": {
-                        "description": "Подписка не найдена",
+                        "description": "API ключ не найден или уже отозван",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Обновить цену или дату окончания подписки",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/api-keys/{id}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Выдать новый ключ вместо старого с теми же областями доступа. Старый ключ сразу перестает действовать",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "subscriptions"
+                    "api-keys"
                 ],
-                "summary": "Обновление данных подписки",
+                "summary": "Смена секрета API ключа",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "ID подписки",
+                        "description": "ID API ключа",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Данные для обновления",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.updateSubInput"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Статус и сообщение",
+                        "description": "Новый ключ",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/domain.IssuedAPIKey"
                         }
                     },
-                    "400": {
-                        "description": "Неверные данные",
+                    "401": {
+                        "description": "Требуется аутентификация",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     },
                     "404": {
-                        "description": "Подписка не найдена",
+                        "description": "API ключ не найден или отозван",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     },
                     "500": {
                         "description": "Внутренняя ошибка сервера",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResponse"
+                            "$ref": "#/definitions/domain.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "details": {
-                    "description": "(Опционально) Детали",
-                    "type": "string",
-                    "example": "email is required"
-                },
-                "error": {
-                    "description": "Краткий код или сообщение",
-                    "type": "string",
-                    "example": "invalid input"
-                }
-            }
-        },
-        "domain.Subscription": {
-            "type": "object",
-            "properties": {
-                "end_date": {
-                    "description": "Дата окончания",
-                    "type": "string"
-                },
-                "price": {
-                    "description": "Цена в рублях",
-                    "type": "integer"
-                },
-                "service_name": {
-                    "description": "Название сервиса",
-                    "type": "string"
-                },
-                "start_date": {
-                    "description": "Дата начала",
-                    "type": "string"
-                },
-                "user_id": {
-                    "description": "UUID пользователя",
-                    "type": "string"
-                }
-            }
         },
-        "handlers.createSubInput": {
+        "/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получить страницу списка подписок пользователя с фильтрацией и сортировкой.\nДля получения следующей страницы передайте next_cursor из ответа в параметр cursor, не меняя остальные параметры",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Получение списка подписок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя, по умолчанию - из токена",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подписка активна в день YYYY-MM-DD, в день момента времени RFC 3339 в часовом поясе tz или хотя бы один день месяца MM-YYYY",
+                        "name": "active_at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальная цена",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальная цена",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Наличие даты окончания",
+                        "name": "has_end_date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "trial",
+                            "active",
+                            "paused",
+                            "cancelled",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "Статус подписки",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "start_date",
+                            "price",
+                            "service_name"
+                        ],
+                        "type": "string",
+                        "default": "start_date",
+                        "description": "Поле сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Направление сортировки",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionPageView"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создать новую подписку. Без end_date подписка бессрочная.\nС заголовком Idempotency-Key повтор запроса того же пользователя с теми же данными возвращает исходный ответ и не создает дубликат",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Создание подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности запроса",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные подписки",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createSubInput"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Созданная подписка",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionView"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Адрес созданной подписки"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверное тело запроса",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Запрос с этим ключом еще выполняется",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Ключ уже использован с другим телом запроса",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/cost-breakdown": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получить стоимость подписок пользователя за выбранный период в виде матрицы: строки - сервисы, столбцы - месяцы.\nДополнительно возвращаются итоги по строкам, столбцам и за весь период",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Разбивка стоимости подписок по месяцам и сервисам",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя, по умолчанию - из токена",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название подписки",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода: дата YYYY-MM-DD, месяц MM-YYYY или момент времени RFC 3339 (дата в часовом поясе tz)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода включительно: дата YYYY-MM-DD, месяц MM-YYYY или момент времени RFC 3339 (дата в часовом поясе tz)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "RUB",
+                        "description": "Валюта отчета (ISO 4217), суммы пересчитываются по загруженным курсам",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "daily"
+                        ],
+                        "type": "string",
+                        "default": "none",
+                        "description": "Пропорциональный расчет: none - списания целиком, daily - по дням активности",
+                        "name": "proration",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.costBreakdownView"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Не найден курс валюты",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/total-cost": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получить суммарную стоимость подписок за выбранный период с фильтрацией по user_id и названию подписки.\nУчитываются все списания, приходящиеся на период: подписка оплачивается в начале каждого расчетного периода, начиная с даты начала.\nКаждое списание считается по цене, действовавшей на дату списания.\nС proration=daily цена расчетного периода распределяется по его дням, и неполные первый и последний месяцы учитываются пропорционально дням активности",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Подсчитать суммарную стоимость подписок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя, по умолчанию - из токена",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название подписки",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода: дата YYYY-MM-DD, месяц MM-YYYY или момент времени RFC 3339 (дата в часовом поясе tz)",
+                        "name": "start_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода включительно: дата YYYY-MM-DD, месяц MM-YYYY или момент времени RFC 3339 (дата в часовом поясе tz)",
+                        "name": "end_date",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "RUB",
+                        "description": "Валюта отчета (ISO 4217), суммы пересчитываются по загруженным курсам",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "none",
+                            "daily"
+                        ],
+                        "type": "string",
+                        "default": "none",
+                        "description": "Пропорциональный расчет: none - списания целиком, daily - по дням активности",
+                        "name": "proration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Суммарная стоимость и валюта",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Не найден курс валюты",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получить страницу списка удаленных подписок пользователя. Параметры те же, что у списка подписок.\nУдаленные подписки не участвуют в отчетах и окончательно удаляются после срока хранения корзины",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Получение корзины подписок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID пользователя, по умолчанию - из токена",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название сервиса",
+                        "name": "service_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Подписка активна в день YYYY-MM-DD, в день момента времени RFC 3339 в часовом поясе tz или хотя бы один день месяца MM-YYYY",
+                        "name": "active_at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Минимальная цена",
+                        "name": "price_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальная цена",
+                        "name": "price_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Наличие даты окончания",
+                        "name": "has_end_date",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "trial",
+                            "active",
+                            "paused",
+                            "cancelled",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "Статус подписки",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "start_date",
+                            "price",
+                            "service_name"
+                        ],
+                        "type": "string",
+                        "default": "start_date",
+                        "description": "Поле сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Направление сортировки",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Размер страницы",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionPageView"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные параметры",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получить информацию о подписке по её ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Получение подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionView"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия подписки для If-Match"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Полностью заменить данные подписки, кроме владельца. Незаданные необязательные поля получают значения по умолчанию,\nбез end_date подписка становится бессрочной",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Замена подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag подписки из предыдущего ответа",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Новые данные подписки",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.replaceSubInput"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленная подписка",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionView"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные данные",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия подписки не совпадает с If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Переместить подписку в корзину. Ее можно восстановить, пока не истек срок хранения корзины",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Удаление подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag подписки из предыдущего ответа",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Подписка перемещена в корзину"
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия подписки не совпадает с If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Частично обновить подписку по правилам JSON Merge Patch (RFC 7396): отсутствующие поля не меняются,\n\"end_date\": null делает подписку бессрочной.\nНовая цена действует с даты effective_from (по умолчанию с текущей даты), уже сделанные списания не меняются",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Обновление данных подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag подписки из предыдущего ответа",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные для обновления",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.updateSubInput"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленная подписка",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionView"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверные данные",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия подписки не совпадает с If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/activate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Перевести подписку из пробного периода в оплачиваемую с текущей даты",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Активация подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag подписки из предыдущего ответа",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка в новом статусе",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionView"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Подписка не в пробном периоде",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия подписки не совпадает с If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отменить подписку. Активная подписка действует до конца оплаченного расчетного периода, пробная - до trial_end_date, если он задан, иначе, как и приостановленная, заканчивается сегодня. Более ранняя дата окончания сохраняется",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Отмена подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag подписки из предыдущего ответа",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка в новом статусе",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionView"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Подписка уже отменена или истекла",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия подписки не совпадает с If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получить все изменения подписки в хронологическом порядке: кто, когда и как ее менял.\nИстория доступна и для удаленных подписок",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "История изменений подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.subscriptionEventView"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "История подписки не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Приостановить активную подписку с текущей даты. Дни паузы не оплачиваются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Приостановка подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag подписки из предыдущего ответа",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка в новом статусе",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionView"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Подписка не активна",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия подписки не совпадает с If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/prices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получить цены подписки с датами, с которых они действуют, в хронологическом порядке",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "История цен подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.subscriptionPriceView"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Восстановить удаленную подписку из корзины",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Восстановление подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Восстановленная подписка",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionView"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена в корзине",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/subscriptions/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возобновить приостановленную подписку с текущей даты",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "subscriptions"
+                ],
+                "summary": "Возобновление подписки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID подписки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag подписки из предыдущего ответа",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "date",
+                            "month",
+                            "datetime"
+                        ],
+                        "type": "string",
+                        "default": "date",
+                        "description": "Формат дат в ответе",
+                        "name": "date_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Часовой пояс IANA для текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC",
+                        "name": "tz",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подписка в новом статусе",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.subscriptionView"
+                        }
+                    },
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Доступ запрещен",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Подписка не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Подписка не приостановлена",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Версия подписки не совпадает с If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много запросов",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Время создания",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID ключа, он же первая часть ключа",
+                    "type": "string",
+                    "example": "8a7c1e52-3f0b-4b8e-9d56-0c4f3b7e2a11"
+                },
+                "last_used_at": {
+                    "description": "Время последнего использования",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Название ключа",
+                    "type": "string",
+                    "example": "billing-batch"
+                },
+                "revoked_at": {
+                    "description": "Время отзыва",
+                    "type": "string"
+                },
+                "rotated_at": {
+                    "description": "Время последней смены секрета",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Области доступа",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Scope"
+                    }
+                },
+                "tenant_id": {
+                    "description": "Организация владельца",
+                    "type": "string",
+                    "example": "default"
+                },
+                "user_id": {
+                    "description": "Владелец ключа",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BillingPeriod": {
+            "type": "string",
+            "enum": [
+                "weekly",
+                "monthly",
+                "quarterly",
+                "yearly"
+            ],
+            "x-enum-varnames": [
+                "BillingWeekly",
+                "BillingMonthly",
+                "BillingQuarterly",
+                "BillingYearly"
+            ]
+        },
+        "domain.ExchangeRate": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "Код валюты (ISO 4217)",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "rate": {
+                    "description": "Количество единиц валюты за единицу базовой валюты",
+                    "type": "number",
+                    "example": 1.0321
+                },
+                "updated_at": {
+                    "description": "Время загрузки курса",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Нарушенное правило",
+                    "type": "string",
+                    "example": "date"
+                },
+                "field": {
+                    "description": "Поле тела или параметр запроса",
+                    "type": "string",
+                    "example": "start_date"
+                },
+                "message": {
+                    "description": "Описание ошибки на языке запроса",
+                    "type": "string",
+                    "example": "Ожидается формат MM-YYYY"
+                },
+                "param": {
+                    "description": "Параметр правила",
+                    "type": "string",
+                    "example": "MM-YYYY"
+                }
+            }
+        },
+        "domain.IssuedAPIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Время создания",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID ключа, он же первая часть ключа",
+                    "type": "string",
+                    "example": "8a7c1e52-3f0b-4b8e-9d56-0c4f3b7e2a11"
+                },
+                "key": {
+                    "description": "Значение для заголовка Authorization: ApiKey",
+                    "type": "string",
+                    "example": "8a7c1e52-3f0b-4b8e-9d56-0c4f3b7e2a11.q2X..."
+                },
+                "last_used_at": {
+                    "description": "Время последнего использования",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Название ключа",
+                    "type": "string",
+                    "example": "billing-batch"
+                },
+                "revoked_at": {
+                    "description": "Время отзыва",
+                    "type": "string"
+                },
+                "rotated_at": {
+                    "description": "Время последней смены секрета",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Области доступа",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Scope"
+                    }
+                },
+                "tenant_id": {
+                    "description": "Организация владельца",
+                    "type": "string",
+                    "example": "default"
+                },
+                "user_id": {
+                    "description": "Владелец ключа",
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Код ошибки для программной обработки",
+                    "type": "string",
+                    "example": "subscription_not_found"
+                },
+                "detail": {
+                    "description": "Пояснение к этому случаю ошибки",
+                    "type": "string"
+                },
+                "errors": {
+                    "description": "Ошибки по полям для ошибок валидации",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldError"
+                    }
+                },
+                "instance": {
+                    "description": "ID запроса",
+                    "type": "string",
+                    "example": "4FJ6M2XQZ7KTB3WD5N8RC1PA"
+                },
+                "status": {
+                    "description": "HTTP статус",
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "description": "Краткое описание типа ошибки",
+                    "type": "string",
+                    "example": "Подписка не найдена"
+                },
+                "type": {
+                    "description": "Тип ошибки, не меняется между версиями",
+                    "type": "string",
+                    "example": "urn:problem:subscription_not_found"
+                }
+            }
+        },
+        "domain.Scope": {
+            "type": "string",
+            "enum": [
+                "subscriptions:read",
+                "subscriptions:write",
+                "reports:read"
+            ],
+            "x-enum-varnames": [
+                "ScopeSubscriptionsRead",
+                "ScopeSubscriptionsWrite",
+                "ScopeReportsRead"
+            ]
+        },
+        "domain.ServiceCostRow": {
+            "type": "object",
+            "properties": {
+                "costs": {
+                    "description": "Стоимость по месяцам, в порядке поля months",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "service_name": {
+                    "description": "Название сервиса",
+                    "type": "string",
+                    "example": "Yandex Plus"
+                },
+                "total": {
+                    "description": "Итог по сервису за период",
+                    "type": "integer",
+                    "example": 3600
+                }
+            }
+        },
+        "domain.Subscription": {
+            "type": "object",
+            "properties": {
+                "billing_period": {
+                    "description": "Расчетный период: weekly, monthly, quarterly, yearly",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.BillingPeriod"
+                        }
+                    ],
+                    "example": "monthly"
+                },
+                "created_at": {
+                    "description": "Время создания",
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Валюта подписки (ISO 4217)",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "deleted_at": {
+                    "description": "Время удаления в корзину",
+                    "type": "string"
+                },
+                "end_date": {
+                    "description": "Дата окончания, включительно",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID подписки",
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
+                "price": {
+                    "description": "Цена за расчетный период по последней записи истории цен",
+                    "type": "integer"
+                },
+                "service_name": {
+                    "description": "Название сервиса",
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "Дата начала, она же дата первого списания",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Статус: trial, active, paused, cancelled, expired",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.SubscriptionStatus"
+                        }
+                    ],
+                    "example": "active"
+                },
+                "trial_end_date": {
+                    "description": "Последний день пробного периода, включительно",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Время последнего изменения",
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "UUID пользователя",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Версия, увеличивается при каждом изменении",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "domain.SubscriptionAction": {
+            "type": "string",
+            "enum": [
+                "created",
+                "updated",
+                "deleted",
+                "restored",
+                "purged",
+                "activated",
+                "paused",
+                "resumed",
+                "cancelled",
+                "expired"
+            ],
+            "x-enum-comments": {
+                "ActionActivated": "Пробный период переведен в оплачиваемый",
+                "ActionExpired": "Отмененная подписка или пробный период закончились"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "",
+                "",
+                "",
+                "Пробный период переведен в оплачиваемый",
+                "",
+                "",
+                "",
+                "Отмененная подписка или пробный период закончились"
+            ],
+            "x-enum-varnames": [
+                "ActionCreated",
+                "ActionUpdated",
+                "ActionDeleted",
+                "ActionRestored",
+                "ActionPurged",
+                "ActionActivated",
+                "ActionPaused",
+                "ActionResumed",
+                "ActionCancelled",
+                "ActionExpired"
+            ]
+        },
+        "domain.SubscriptionStatus": {
+            "type": "string",
+            "enum": [
+                "trial",
+                "active",
+                "paused",
+                "cancelled",
+                "expired"
+            ],
+            "x-enum-comments": {
+                "StatusActive": "Действует и оплачивается",
+                "StatusCancelled": "Отменена, действует до end_date",
+                "StatusExpired": "Завершена",
+                "StatusPaused": "Приостановлена, не оплачивается",
+                "StatusTrial": "Пробный период, не оплачивается"
+            },
+            "x-enum-descriptions": [
+                "Пробный период, не оплачивается",
+                "Действует и оплачивается",
+                "Приостановлена, не оплачивается",
+                "Отменена, действует до end_date",
+                "Завершена"
+            ],
+            "x-enum-varnames": [
+                "StatusTrial",
+                "StatusActive",
+                "StatusPaused",
+                "StatusCancelled",
+                "StatusExpired"
+            ]
+        },
+        "handlers.costBreakdownView": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "Валюта отчета",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "month_totals": {
+                    "description": "Итоги по месяцам, в порядке поля months",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "months": {
+                    "description": "Месяцы периода",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "2025-01",
+                        "2025-02"
+                    ]
+                },
+                "services": {
+                    "description": "Строки матрицы по сервисам",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ServiceCostRow"
+                    }
+                },
+                "total": {
+                    "description": "Итог за период",
+                    "type": "integer",
+                    "example": 7200
+                }
+            }
+        },
+        "handlers.createAPIKeyInput": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "billing-batch"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "subscriptions:read",
+                        "reports:read"
+                    ]
+                }
+            }
+        },
+        "handlers.createSubInput": {
             "type": "object",
             "required": [
                 "price",
@@ -352,31 +2650,313 @@ const docTemplate = `{
                 "user_id"
             ],
             "properties": {
+                "billing_period": {
+                    "type": "string",
+                    "default": "monthly",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ]
+                },
+                "currency": {
+                    "type": "string",
+                    "default": "RUB",
+                    "example": "RUB"
+                },
+                "end_date": {
+                    "description": "Дата YYYY-MM-DD, месяц MM-YYYY (его последний день) или момент времени RFC 3339 (дата в часовом поясе tz), включительно",
+                    "type": "string",
+                    "example": "2025-12-31"
+                },
+                "price": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "service_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "start_date": {
+                    "description": "Дата YYYY-MM-DD, месяц MM-YYYY (его первый день) или момент времени RFC 3339 (дата в часовом поясе tz)",
+                    "type": "string",
+                    "example": "2025-07-15"
+                },
+                "status": {
+                    "description": "Начальный статус, по умолчанию trial при trial_end_date, иначе active",
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "trial"
+                    ]
+                },
+                "trial_end_date": {
+                    "description": "Последний день пробного периода, включительно: дата YYYY-MM-DD, месяц MM-YYYY (его последний день) или момент времени RFC 3339 (дата в часовом поясе tz)",
+                    "type": "string",
+                    "example": "2025-07-29"
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "handlers.replaceSubInput": {
+            "type": "object",
+            "required": [
+                "price",
+                "service_name",
+                "start_date"
+            ],
+            "properties": {
+                "billing_period": {
+                    "type": "string",
+                    "default": "monthly",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ]
+                },
+                "currency": {
+                    "type": "string",
+                    "default": "RUB",
+                    "example": "RUB"
+                },
+                "end_date": {
+                    "description": "Дата YYYY-MM-DD, месяц MM-YYYY (его последний день) или момент времени RFC 3339 (дата в часовом поясе tz), включительно",
+                    "type": "string",
+                    "example": "2025-12-31"
+                },
+                "price": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "service_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "start_date": {
+                    "description": "Дата YYYY-MM-DD, месяц MM-YYYY (его первый день) или момент времени RFC 3339 (дата в часовом поясе tz)",
+                    "type": "string",
+                    "example": "2025-07-15"
+                }
+            }
+        },
+        "handlers.subscriptionEventView": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Вид изменения: created, updated, deleted, restored, purged, activated, paused, resumed, cancelled, expired",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.SubscriptionAction"
+                        }
+                    ],
+                    "example": "updated"
+                },
+                "actor": {
+                    "description": "Инициатор изменения",
+                    "type": "string"
+                },
+                "after": {
+                    "description": "Состояние после изменения",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.subscriptionView"
+                        }
+                    ]
+                },
+                "before": {
+                    "description": "Состояние до изменения",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/handlers.subscriptionView"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "description": "Время изменения",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Порядковый номер записи",
+                    "type": "integer",
+                    "example": 1
+                },
+                "subscription_id": {
+                    "description": "ID подписки",
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                }
+            }
+        },
+        "handlers.subscriptionPageView": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "Подписки страницы",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.subscriptionView"
+                    }
+                },
+                "next_cursor": {
+                    "description": "Курсор следующей страницы, пустой на последней странице",
+                    "type": "string",
+                    "example": "eyJz..."
+                }
+            }
+        },
+        "handlers.subscriptionPriceView": {
+            "type": "object",
+            "properties": {
+                "effective_from": {
+                    "description": "Дата, с которой действует цена",
+                    "type": "string",
+                    "example": "2025-09-01"
+                },
+                "price": {
+                    "description": "Цена за расчетный период в валюте подписки",
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.subscriptionView": {
+            "type": "object",
+            "properties": {
+                "billing_period": {
+                    "description": "Расчетный период: weekly, monthly, quarterly, yearly",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.BillingPeriod"
+                        }
+                    ],
+                    "example": "monthly"
+                },
+                "created_at": {
+                    "description": "Время создания",
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Валюта подписки (ISO 4217)",
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "deleted_at": {
+                    "description": "Время удаления в корзину",
+                    "type": "string"
+                },
+                "end_date": {
+                    "description": "Дата окончания, включительно",
+                    "type": "string",
+                    "example": "2025-12-31"
+                },
+                "id": {
+                    "description": "ID подписки",
+                    "type": "string",
+                    "example": "60601fee-2bf1-4721-ae6f-7636e79a0cba"
+                },
                 "price": {
+                    "description": "Цена за расчетный период по последней записи истории цен",
                     "type": "integer"
                 },
                 "service_name": {
+                    "description": "Название сервиса",
                     "type": "string"
                 },
                 "start_date": {
+                    "description": "Дата начала, она же дата первого списания",
+                    "type": "string",
+                    "example": "2025-07-15"
+                },
+                "status": {
+                    "description": "Статус: trial, active, paused, cancelled, expired",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.SubscriptionStatus"
+                        }
+                    ],
+                    "example": "active"
+                },
+                "trial_end_date": {
+                    "description": "Последний день пробного периода, включительно",
+                    "type": "string",
+                    "example": "2025-07-29"
+                },
+                "updated_at": {
+                    "description": "Время последнего изменения",
                     "type": "string"
                 },
                 "user_id": {
+                    "description": "UUID пользователя",
                     "type": "string"
+                },
+                "version": {
+                    "description": "Версия, увеличивается при каждом изменении",
+                    "type": "integer",
+                    "example": 1
                 }
             }
         },
         "handlers.updateSubInput": {
             "type": "object",
             "properties": {
+                "billing_period": {
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "monthly",
+                        "quarterly",
+                        "yearly"
+                    ]
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "RUB"
+                },
+                "effective_from": {
+                    "description": "Дата YYYY-MM-DD, месяц MM-YYYY (его первый день) или момент времени RFC 3339 (дата в часовом поясе tz), с которой действует новая цена, по умолчанию текущая дата",
+                    "type": "string",
+                    "example": "2025-09-01"
+                },
                 "end_date": {
-                    "type": "string"
+                    "description": "Дата YYYY-MM-DD, месяц MM-YYYY (его последний день) или момент времени RFC 3339 (дата в часовом поясе tz), включительно",
+                    "type": "string",
+                    "example": "2025-12-31"
                 },
                 "price": {
-                    "type": "integer"
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "service_name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "start_date": {
+                    "description": "Дата YYYY-MM-DD, месяц MM-YYYY (его первый день) или момент времени RFC 3339 (дата в часовом поясе tz)",
+                    "type": "string",
+                    "example": "2025-07-15"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API ключ в формате \"ApiKey \u003ckey\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT токен доступа в формате \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -10,12 +10,17 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/levinOo/go-crudl-task/internal/auth"
 	"github.com/levinOo/go-crudl-task/internal/config"
 	"github.com/levinOo/go-crudl-task/internal/db"
+	"github.com/levinOo/go-crudl-task/internal/domain"
 	"github.com/levinOo/go-crudl-task/internal/handlers"
+	"github.com/levinOo/go-crudl-task/internal/policy"
 	"github.com/levinOo/go-crudl-task/internal/repository"
 	"github.com/levinOo/go-crudl-task/internal/service"
+	"github.com/levinOo/go-crudl-task/pkg/eventbus"
 	"github.com/levinOo/go-crudl-task/pkg/logger"
+	"github.com/levinOo/go-crudl-task/pkg/ratelimit"
 
 	"github.com/gin-gonic/gin"
 )
@@ -34,6 +39,32 @@ func Run() error {
 
 	log.Info("URL Database", slog.String("url", cfg.Postgre.URL))
 
+	// Ключи проверки токенов доступа
+	tokens, err := auth.NewVerifier(auth.Config{
+		HMACSecret:   cfg.Auth.HMACSecret,
+		RSAPublicKey: cfg.Auth.RSAPublicKey,
+		JWKSFile:     cfg.Auth.JWKSFile,
+		Issuer:       cfg.Auth.Issuer,
+		Audience:     cfg.Auth.Audience,
+	})
+	if err != nil {
+		log.Error("Не удалось загрузить ключи JWT", slog.String("error", err.Error()))
+		return err
+	}
+
+	// Ограничения частоты запросов
+	limits := handlers.RateLimits{
+		Auth:          authRateLimit(cfg.Server.RateLimit.Auth),
+		Subscriptions: rateLimit(cfg.Server.RateLimit.Subscriptions),
+		Reports:       rateLimit(cfg.Server.RateLimit.Reports),
+		APIKeys:       rateLimit(cfg.Server.RateLimit.APIKeys),
+		Admin:         rateLimit(cfg.Server.RateLimit.Admin),
+	}
+	if err := limits.Validate(); err != nil {
+		log.Error("Неверная конфигурация ограничения запросов", slog.String("error", err.Error()))
+		return err
+	}
+
 	// Подключаем базу данных
 	pgCfg := db.Config{
 		URL:            cfg.Postgre.URL,
@@ -41,6 +72,7 @@ func Run() error {
 		RetryAttempts:  cfg.Postgre.RetryAttempts,
 		RetryDelay:     cfg.Postgre.RetryDelay,
 		ConnectTimeout: cfg.Postgre.ContextTimeoutValue,
+		SessionTenant:  domain.TenantFromContext,
 	}
 
 	pg, err := db.New(pgCfg, log)
@@ -56,14 +88,33 @@ func Run() error {
 		return err
 	}
 
+	// Шина событий подписок. Код уведомлений подписывается на нее здесь
+	events := eventbus.New[domain.SubscriptionEvent]()
+	events.Subscribe(logSubscriptionEvent(log))
+
 	// Dependency Injection
 	repo := repository.NewRepositories(pg)
 
 	deps := service.Deps{
-		Repos: *repo,
+		Repos:          *repo,
+		IdempotencyTTL: cfg.Idempotency.TTL,
+		TrashRetention: cfg.Trash.Retention,
+		Publisher:      events,
 	}
 	services := service.NewServices(deps)
-	h := handlers.NewHandler(services.Subscription, log)
+	h := handlers.NewHandler(handlers.Deps{
+		Subscriptions: policy.NewSubscriptionPolicy(services.Subscription, log),
+		ExchangeRates: services.ExchangeRate,
+		Idempotency:   services.Idempotency,
+		APIKeys:       services.APIKey,
+		Tokens:        tokens,
+		TenantHeader:  cfg.Tenant.Header,
+		DefaultTenant: cfg.Tenant.Default,
+		TrustTenant:   cfg.Tenant.TrustHeader,
+		RateLimiter:   ratelimit.NewMemoryStore(),
+		RateLimits:    limits,
+		Log:           log,
+	})
 
 	// Устанавливаем режим работы сервера
 	if cfg.Env == "prod" {
@@ -76,6 +127,13 @@ func Run() error {
 
 	// Инициализация роутеров
 	router := gin.New()
+
+	// Адрес клиента берется из X-Forwarded-For только от доверенных прокси, иначе - адрес соединения
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Error("Неверный список доверенных прокси", slog.String("error", err.Error()))
+		return err
+	}
+
 	h.InitRoutes(router)
 
 	// Конфигурация HTTP сервера
@@ -100,6 +158,16 @@ func Run() error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// Фоновое завершение пробных периодов
+	if cfg.Trials.Interval > 0 {
+		go runTrials(ctx, services.Subscription, cfg.Trials.Interval, log)
+	}
+
+	// Фоновое удаление истекших ключей идемпотентности
+	if cfg.Idempotency.PurgeInterval > 0 {
+		go runIdempotencyPurge(ctx, services.Idempotency, cfg.Idempotency.PurgeInterval, log)
+	}
+
 	// Ожидание сигнала остановки
 	<-ctx.Done()
 
@@ -118,3 +186,18 @@ func Run() error {
 
 	return nil
 }
+
+// Ограничение частоты запросов группы маршрутов из конфигурации
+func rateLimit(rule config.RateLimitRule) handlers.RateLimit {
+	return handlers.RateLimit{
+		Limit: ratelimit.Limit{Requests: rule.Requests, Period: rule.Period},
+		By:    rule.By,
+	}
+}
+
+// Ограничение частоты запросов до аутентификации: пользователь еще неизвестен, поэтому только по IP
+func authRateLimit(rule config.RateLimitRule) handlers.RateLimit {
+	limit := rateLimit(rule)
+	limit.By = handlers.RateLimitByIP
+	return limit
+}
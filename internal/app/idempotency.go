@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Сервис, удаляющий истекшие ключи идемпотентности
+type idempotencyPurger interface {
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// Удаление истекших ключей идемпотентности сразу после запуска и затем каждые interval до отмены ctx.
+// Ошибки только логируются: оставшиеся ключи будут удалены при следующем запуске
+func runIdempotencyPurge(ctx context.Context, keys idempotencyPurger, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := keys.PurgeExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("Ошибка при удалении истекших ключей идемпотентности", slog.String("error", err.Error()))
+		}
+		if count > 0 {
+			log.Info("Истекшие ключи идемпотентности удалены", slog.Int64("count", count))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
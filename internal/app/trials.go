@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+	"github.com/levinOo/go-crudl-task/pkg/eventbus"
+)
+
+// Сервис, завершающий пробные периоды
+type trialCompleter interface {
+	CompleteTrials(ctx context.Context) (int, error)
+}
+
+// Завершение пробных периодов сразу после запуска и затем каждые interval до отмены ctx.
+// Ошибки только логируются: необработанные подписки будут выбраны при следующем запуске
+func runTrials(ctx context.Context, trials trialCompleter, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := trials.CompleteTrials(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("Ошибка при завершении пробных периодов", slog.String("error", err.Error()))
+		}
+		if count > 0 {
+			log.Info("Статусы подписок обновлены по дате", slog.Int("count", count))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Обработчик событий подписок, записывающий их в лог
+func logSubscriptionEvent(log *slog.Logger) eventbus.Handler[domain.SubscriptionEvent] {
+	return func(ctx context.Context, event domain.SubscriptionEvent) error {
+		log.Info("Событие подписки",
+			slog.String("tenant_id", domain.TenantFromContext(ctx)),
+			slog.String("subscription_id", event.SubscriptionID),
+			slog.String("action", string(event.Action)),
+		)
+		return nil
+	}
+}
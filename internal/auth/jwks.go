@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Ошибка чтения файла JWKS
+var ErrInvalidJWKS = errors.New("неверный формат JWKS")
+
+// Ключ JWKS (RFC 7517). Поддерживаются RSA и симметричные ключи
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+// Разбор JWKS в ключи проверки подписи по kid.
+// Ключи с use, отличным от sig, пропускаются
+func parseJWKS(data []byte) (map[string]any, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJWKS, err)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+
+	for _, k := range set.Keys {
+		if k.Kid == "" {
+			return nil, fmt.Errorf("%w: ключ без kid", ErrInvalidJWKS)
+		}
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		switch k.Kty {
+		case "RSA":
+			key, err := rsaKeyFromJWK(k)
+			if err != nil {
+				return nil, fmt.Errorf("%w: ключ %s: %w", ErrInvalidJWKS, k.Kid, err)
+			}
+			keys[k.Kid] = key
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("%w: ключ %s: неверное значение k", ErrInvalidJWKS, k.Kid)
+			}
+			keys[k.Kid] = secret
+		default:
+			return nil, fmt.Errorf("%w: ключ %s: неподдерживаемый тип %q", ErrInvalidJWKS, k.Kid, k.Kty)
+		}
+	}
+
+	return keys, nil
+}
+
+// Сборка публичного ключа RSA из модуля n и экспоненты e
+func rsaKeyFromJWK(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil || len(n) == 0 {
+		return nil, errors.New("неверное значение n")
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("неверное значение e")
+	}
+
+	exponent := 0
+	for _, b := range e {
+		exponent = exponent<<8 | int(b)
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}, nil
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Ошибки
+var (
+	ErrNoKeys      = errors.New("не задан ни один ключ проверки JWT")
+	ErrUnknownKey  = errors.New("неизвестный ключ подписи JWT")
+	ErrNoSubject   = errors.New("в JWT нет subject")
+	ErrUnknownRole = errors.New("неизвестная роль в JWT")
+)
+
+// Конфигурация проверки JWT
+type Config struct {
+	HMACSecret   string // Секрет для HS256
+	RSAPublicKey string // Публичный ключ для RS256 в формате PEM
+	JWKSFile     string // Путь к локальному файлу JWKS
+	Issuer       string // Ожидаемый iss, пустой - не проверяется
+	Audience     string // Ожидаемый aud, пустой - не проверяется
+}
+
+// Claims токена доступа
+type claims struct {
+	Role     string `json:"role"`
+	TenantID string `json:"tenant_id"`
+	TimeZone string `json:"zoneinfo"`
+	jwt.RegisteredClaims
+}
+
+// Проверка JWT токенов доступа
+type Verifier struct {
+	hmacSecret []byte
+	rsaKey     *rsa.PublicKey
+	keys       map[string]any // Ключи JWKS по kid
+	parser     *jwt.Parser
+}
+
+// Функция конструктор.
+// Ключи берутся из конфигурации и из файла JWKS, нужен хотя бы один
+func NewVerifier(cfg Config) (*Verifier, error) {
+	v := &Verifier{
+		keys: make(map[string]any),
+	}
+
+	if cfg.HMACSecret != "" {
+		v.hmacSecret = []byte(cfg.HMACSecret)
+	}
+
+	if cfg.RSAPublicKey != "" {
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cfg.RSAPublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("Ошибка при чтении публичного ключа RSA: %w", err)
+		}
+		v.rsaKey = key
+	}
+
+	if cfg.JWKSFile != "" {
+		data, err := os.ReadFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("Ошибка при чтении файла JWKS: %w", err)
+		}
+
+		keys, err := parseJWKS(data)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+	}
+
+	if v.hmacSecret == nil && v.rsaKey == nil && len(v.keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(options...)
+
+	return v, nil
+}
+
+// Проверка токена и получение пользователя из него.
+// Пользователь берется из sub в нижнем регистре (UUID хранятся так), роль - из claim role (по умолчанию user)
+func (v *Verifier) Verify(tokenString string) (domain.Principal, error) {
+	var c claims
+
+	if _, err := v.parser.ParseWithClaims(tokenString, &c, v.key); err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: %w", domain.ErrUnauthorized, err)
+	}
+
+	if c.Subject == "" {
+		return domain.Principal{}, fmt.Errorf("%w: %w", domain.ErrUnauthorized, ErrNoSubject)
+	}
+
+	role := domain.Role(c.Role)
+	switch role {
+	case "":
+		role = domain.RoleUser
+	case domain.RoleUser, domain.RoleSupport, domain.RoleAdmin:
+	default:
+		return domain.Principal{}, fmt.Errorf("%w: %w", domain.ErrUnauthorized, ErrUnknownRole)
+	}
+
+	return domain.Principal{UserID: strings.ToLower(c.Subject), Role: role, TenantID: c.TenantID, TimeZone: c.TimeZone}, nil
+}
+
+// Выбор ключа проверки подписи: по kid из JWKS, иначе по алгоритму из конфигурации.
+// Соответствие типа ключа алгоритму проверяет сама библиотека
+func (v *Verifier) key(token *jwt.Token) (any, error) {
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		key, ok := v.keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		return key, nil
+	}
+
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if v.hmacSecret != nil {
+			return v.hmacSecret, nil
+		}
+	case *jwt.SigningMethodRSA:
+		if v.rsaKey != nil {
+			return v.rsaKey, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
@@ -10,19 +10,43 @@ import (
 
 // Конфигурация приложения
 type Config struct {
-	Env     string        `yaml:"env" env-default:"local"`
-	Server  ServerConfig  `yaml:"server"`
-	Postgre PostgreConfig `yaml:"postgre"`
+	Env         string            `yaml:"env" env-default:"local"`
+	Server      ServerConfig      `yaml:"server"`
+	Postgre     PostgreConfig     `yaml:"postgre"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Trash       TrashConfig       `yaml:"trash"`
+	Trials      TrialsConfig      `yaml:"trials"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Tenant      TenantConfig      `yaml:"tenant"`
 }
 
 // Конфигурация сервера
 type ServerConfig struct {
-	ServerPort           string        `env:"APP_PORT" env-default:":8080"`
-	ServerMode           string        `yaml:"server_mode" env:"SERVER_MODE" env-default:"debug"`
-	ReadTimeout          time.Duration `yaml:"read_timeout" env:"READ_TIMEOUT" env-default:"10s"`
-	WriteTimeout         time.Duration `yaml:"write_timeout" env:"WRITE_TIMEOUT" env-default:"10s"`
-	IdleTimeout          time.Duration `yaml:"idle_timeout" env:"IDLE_TIMEOUT" env-default:"60s"`
-	ShutdownContextValue time.Duration `yaml:"shutdown_context_value" env:"SHUTDOWN_CONTEXT_VALUE" env-default:"5s"`
+	ServerPort           string          `env:"APP_PORT" env-default:":8080"`
+	ServerMode           string          `yaml:"server_mode" env:"SERVER_MODE" env-default:"debug"`
+	ReadTimeout          time.Duration   `yaml:"read_timeout" env:"READ_TIMEOUT" env-default:"10s"`
+	WriteTimeout         time.Duration   `yaml:"write_timeout" env:"WRITE_TIMEOUT" env-default:"10s"`
+	IdleTimeout          time.Duration   `yaml:"idle_timeout" env:"IDLE_TIMEOUT" env-default:"60s"`
+	ShutdownContextValue time.Duration   `yaml:"shutdown_context_value" env:"SHUTDOWN_CONTEXT_VALUE" env-default:"5s"`
+	TrustedProxies       []string        `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" env-separator:","` // Прокси, чьим X-Forwarded-For можно верить, пустой - никаким
+	RateLimit            RateLimitConfig `yaml:"rate_limit"`
+}
+
+// Ограничения частоты запросов по группам маршрутов
+type RateLimitConfig struct {
+	Auth          RateLimitRule `yaml:"auth" env-prefix:"RATE_LIMIT_AUTH_"` // Все запросы до проверки учетных данных, by не используется: всегда ip
+	Subscriptions RateLimitRule `yaml:"subscriptions" env-prefix:"RATE_LIMIT_SUBSCRIPTIONS_"`
+	Reports       RateLimitRule `yaml:"reports" env-prefix:"RATE_LIMIT_REPORTS_"`
+	APIKeys       RateLimitRule `yaml:"api_keys" env-prefix:"RATE_LIMIT_API_KEYS_"`
+	Admin         RateLimitRule `yaml:"admin" env-prefix:"RATE_LIMIT_ADMIN_"`
+}
+
+// Ограничение частоты запросов группы маршрутов.
+// Requests равное 0 отключает ограничение
+type RateLimitRule struct {
+	Requests int           `yaml:"requests" env:"REQUESTS"`
+	Period   time.Duration `yaml:"period" env:"PERIOD" env-default:"1m"`
+	By       string        `yaml:"by" env:"BY" env-default:"client"` // По чему считаются запросы: ip, user или client (API ключ, иначе пользователь)
 }
 
 // Конфигурация базы данных
@@ -34,6 +58,42 @@ type PostgreConfig struct {
 	ContextTimeoutValue time.Duration `yaml:"context_timeout_value" env:"POSTGRES_CONTEXT_TIMEOUT_VALUE" env-default:"5s"`
 }
 
+// Конфигурация ключей идемпотентности.
+// PurgeInterval равный 0 отключает фоновое удаление истекших ключей
+type IdempotencyConfig struct {
+	TTL           time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL" env-default:"24h"`
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"IDEMPOTENCY_PURGE_INTERVAL" env-default:"1h"`
+}
+
+// Конфигурация корзины удаленных подписок
+type TrashConfig struct {
+	Retention time.Duration `yaml:"retention" env:"TRASH_RETENTION" env-default:"720h"`
+}
+
+// Конфигурация фонового завершения пробных периодов.
+// Interval равный 0 отключает задачу
+type TrialsConfig struct {
+	Interval time.Duration `yaml:"interval" env:"TRIALS_INTERVAL" env-default:"1h"`
+}
+
+// Конфигурация проверки JWT. Нужен хотя бы один источник ключей
+type AuthConfig struct {
+	HMACSecret   string `yaml:"hmac_secret" env:"JWT_HMAC_SECRET"`
+	RSAPublicKey string `yaml:"rsa_public_key" env:"JWT_RSA_PUBLIC_KEY"`
+	JWKSFile     string `yaml:"jwks_file" env:"JWT_JWKS_FILE"`
+	Issuer       string `yaml:"issuer" env:"JWT_ISSUER"`
+	Audience     string `yaml:"audience" env:"JWT_AUDIENCE"`
+}
+
+// Конфигурация организаций.
+// Организация берется из claim tenant_id токена, иначе используется организация по умолчанию.
+// Заголовок выбирает организацию только при TrustHeader: его должен выставлять доверенный шлюз
+type TenantConfig struct {
+	Header      string `yaml:"header" env:"TENANT_HEADER" env-default:"X-Tenant-ID"`
+	Default     string `yaml:"default" env:"TENANT_DEFAULT" env-default:"default"`
+	TrustHeader bool   `yaml:"trust_header" env:"TENANT_TRUST_HEADER" env-default:"false"`
+}
+
 // Загрузка конфигурации
 func LoadConfig() (*Config, error) {
 	configPath := os.Getenv("CONFIG_PATH")
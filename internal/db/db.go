@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/avast/retry-go"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,6 +18,10 @@ type Config struct {
 	ConnectTimeout time.Duration
 	RetryAttempts  int
 	RetryDelay     time.Duration
+
+	// Организация запроса из контекста. Перед каждым запросом она записывается
+	// в параметр сессии app.tenant_id, на который опираются политики RLS
+	SessionTenant func(ctx context.Context) string
 }
 
 // Структура базы данных
@@ -35,6 +40,15 @@ func New(cfg Config, log *slog.Logger) (*Postgres, error) {
 
 	poolConfig.MaxConns = int32(cfg.PoolMax)
 
+	if cfg.SessionTenant != nil {
+		poolConfig.PrepareConn = func(ctx context.Context, conn *pgx.Conn) (bool, error) {
+			if _, err := conn.Exec(ctx, "SELECT set_config('app.tenant_id', $1, false)", cfg.SessionTenant(ctx)); err != nil {
+				return false, fmt.Errorf("postgres - set app.tenant_id: %w", err)
+			}
+			return true, nil
+		}
+	}
+
 	err = retry.Do(
 		func() error {
 			ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Ошибки
+var (
+	ErrAPIKeyNotFound = errors.New("API ключ не найден")
+	ErrInvalidAPIKey  = errors.New("неверный API ключ")
+	ErrInvalidScope   = errors.New("неизвестная область доступа API ключа")
+)
+
+// Область доступа API ключа
+type Scope string
+
+// Области доступа
+const (
+	ScopeSubscriptionsRead  Scope = "subscriptions:read"
+	ScopeSubscriptionsWrite Scope = "subscriptions:write"
+	ScopeReportsRead        Scope = "reports:read"
+)
+
+// Проверка области доступа
+func (s Scope) Valid() bool {
+	switch s {
+	case ScopeSubscriptionsRead, ScopeSubscriptionsWrite, ScopeReportsRead:
+		return true
+	}
+
+	return false
+}
+
+// API ключ для межсервисных вызовов.
+// Сам ключ не хранится: хранится только его хеш с солью
+type APIKey struct {
+	ID         string     `json:"id" example:"8a7c1e52-3f0b-4b8e-9d56-0c4f3b7e2a11"` // ID ключа, он же первая часть ключа
+	UserID     string     `json:"user_id"`                                           // Владелец ключа
+	TenantID   string     `json:"tenant_id" example:"default"`                       // Организация владельца
+	Name       string     `json:"name" example:"billing-batch"`                      // Название ключа
+	Scopes     []Scope    `json:"scopes"`                                            // Области доступа
+	CreatedAt  time.Time  `json:"created_at"`                                        // Время создания
+	RotatedAt  *time.Time `json:"rotated_at,omitempty"`                              // Время последней смены секрета
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`                            // Время последнего использования
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`                              // Время отзыва
+	Salt       []byte     `json:"-"`                                                 // Соль хеша секрета
+	SecretHash []byte     `json:"-"`                                                 // SHA-256 от соли и секрета
+}
+
+// Только что созданный или обновленный API ключ вместе с самим ключом.
+// Ключ возвращается один раз и больше нигде не доступен
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"8a7c1e52-3f0b-4b8e-9d56-0c4f3b7e2a11.q2X..."` // Значение для заголовка Authorization: ApiKey
+}
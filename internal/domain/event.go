@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Вид изменения подписки
+type SubscriptionAction string
+
+const (
+	ActionCreated  SubscriptionAction = "created"
+	ActionUpdated  SubscriptionAction = "updated"
+	ActionDeleted  SubscriptionAction = "deleted"
+	ActionRestored SubscriptionAction = "restored"
+	ActionPurged   SubscriptionAction = "purged"
+
+	ActionActivated SubscriptionAction = "activated" // Пробный период переведен в оплачиваемый
+	ActionPaused    SubscriptionAction = "paused"
+	ActionResumed   SubscriptionAction = "resumed"
+	ActionCancelled SubscriptionAction = "cancelled"
+	ActionExpired   SubscriptionAction = "expired" // Отмененная подписка или пробный период закончились
+)
+
+// Инициатор изменений, которые выполняют фоновые задачи
+const SystemActor = "system"
+
+// Запись истории изменений подписки
+type SubscriptionEvent struct {
+	ID             int64              `json:"id" example:"1"`                                                 // Порядковый номер записи
+	SubscriptionID string             `json:"subscription_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"` // ID подписки
+	Action         SubscriptionAction `json:"action" example:"updated"`                                       // Вид изменения: created, updated, deleted, restored, purged, activated, paused, resumed, cancelled, expired
+	Actor          string             `json:"actor,omitempty"`                                                // Инициатор изменения
+	Before         *Subscription      `json:"before,omitempty"`                                               // Состояние до изменения
+	After          *Subscription      `json:"after,omitempty"`                                                // Состояние после изменения
+	CreatedAt      time.Time          `json:"created_at"`                                                     // Время изменения
+}
+
+// Ключ инициатора изменений в контексте
+type actorKey struct{}
+
+// Контекст с инициатором изменений
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Инициатор изменений из контекста, пустая строка - неизвестен
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
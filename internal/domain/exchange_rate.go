@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Ошибки
+var (
+	ErrInvalidRatesFile = errors.New("неверный формат файла курсов валют")
+)
+
+// Форматы файлов курсов валют
+const (
+	RatesFormatECB = "ecb" // XML в формате Европейского центрального банка
+	RatesFormatCSV = "csv" // CSV со строками вида currency,rate
+)
+
+// Курс валюты относительно базовой валюты
+type ExchangeRate struct {
+	Currency  string    `json:"currency" example:"USD"` // Код валюты (ISO 4217)
+	Rate      float64   `json:"rate" example:"1.0321"`  // Количество единиц валюты за единицу базовой валюты
+	UpdatedAt time.Time `json:"updated_at"`             // Время загрузки курса
+}
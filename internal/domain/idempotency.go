@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Ошибки
+var (
+	ErrIdempotencyKeyReused  = errors.New("ключ идемпотентности уже использован с другим телом запроса")
+	ErrIdempotencyInProgress = errors.New("запрос с этим ключом идемпотентности еще выполняется")
+)
+
+// Запись о запросе с ключом идемпотентности
+type IdempotencyRecord struct {
+	UserID      string    // Пользователь, отправивший запрос
+	Key         string    // Значение заголовка Idempotency-Key
+	RequestHash string    // SHA-256 тела запроса в hex
+	StatusCode  int       // Код сохраненного ответа, 0 - запрос еще выполняется
+	Response    []byte    // Тело сохраненного ответа
+	ExpiresAt   time.Time // Время, после которого ключ можно использовать заново
+}
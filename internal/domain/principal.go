@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// Ошибки
+var (
+	ErrUnauthorized = errors.New("требуется аутентификация")
+	ErrForbidden    = errors.New("доступ запрещен")
+)
+
+// Роль пользователя API
+type Role string
+
+// Роли
+const (
+	RoleUser    Role = "user"    // Работает только со своими подписками
+	RoleSupport Role = "support" // Читает подписки любых пользователей, ничего не меняет
+	RoleAdmin   Role = "admin"   // Полный доступ, включая отчеты по любым пользователям
+)
+
+// Аутентифицированный пользователь API
+type Principal struct {
+	UserID   string  // UUID пользователя
+	Role     Role    // Роль пользователя
+	TenantID string  // Организация пользователя
+	TimeZone string  // Часовой пояс пользователя (IANA), пустой - не задан
+	APIKeyID string  // ID API ключа, пустой при входе по JWT
+	Scopes   []Scope // Области доступа API ключа
+}
+
+// Пользователь может работать с данными любых пользователей
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// Пользователь вошел по API ключу
+func (p Principal) IsAPIKey() bool {
+	return p.APIKeyID != ""
+}
+
+// Пользователю разрешена область доступа.
+// Вход по JWT не ограничен областями, API ключ - только своими
+func (p Principal) HasScope(scope Scope) bool {
+	if !p.IsAPIKey() {
+		return true
+	}
+
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Ключ пользователя в контексте
+type principalKey struct{}
+
+// Контекст с аутентифицированным пользователем
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// Аутентифицированный пользователь из контекста
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
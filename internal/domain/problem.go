@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Ошибки
+var ErrValidation = errors.New("неверные данные запроса")
+
+// Ответ об ошибке в формате RFC 7807 (application/problem+json)
+type Problem struct {
+	Type     string       `json:"type" example:"urn:problem:subscription_not_found"`     // Тип ошибки, не меняется между версиями
+	Code     string       `json:"code" example:"subscription_not_found"`                 // Код ошибки для программной обработки
+	Title    string       `json:"title" example:"Подписка не найдена"`                   // Краткое описание типа ошибки
+	Status   int          `json:"status" example:"404"`                                  // HTTP статус
+	Detail   string       `json:"detail,omitempty"`                                      // Пояснение к этому случаю ошибки
+	Instance string       `json:"instance,omitempty" example:"4FJ6M2XQZ7KTB3WD5N8RC1PA"` // ID запроса
+	Errors   []FieldError `json:"errors,omitempty"`                                      // Ошибки по полям для ошибок валидации
+}
+
+// Ошибка значения поля запроса
+type FieldError struct {
+	Field   string `json:"field" example:"start_date"`                 // Поле тела или параметр запроса
+	Code    string `json:"code" example:"date"`                        // Нарушенное правило
+	Param   string `json:"param,omitempty" example:"MM-YYYY"`          // Параметр правила
+	Message string `json:"message" example:"Ожидается формат MM-YYYY"` // Описание ошибки на языке запроса
+}
+
+// Ошибка валидации с ошибками по полям. Сравнивается с ErrValidation через errors.Is
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Функция конструктор
+func NewValidationError(fields ...FieldError) *ValidationError {
+	return &ValidationError{Fields: fields}
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + ": " + field.Code
+		if field.Param != "" {
+			messages[i] += "=" + field.Param
+		}
+	}
+
+	return ErrValidation.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
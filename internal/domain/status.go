@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Ошибки
+var (
+	ErrInvalidStatus     = errors.New("неверный начальный статус подписки")
+	ErrInvalidTransition = errors.New("переход подписки в этот статус недопустим")
+)
+
+// Статус подписки
+type SubscriptionStatus string
+
+// Статусы подписки
+const (
+	StatusTrial     SubscriptionStatus = "trial"     // Пробный период, не оплачивается
+	StatusActive    SubscriptionStatus = "active"    // Действует и оплачивается
+	StatusPaused    SubscriptionStatus = "paused"    // Приостановлена, не оплачивается
+	StatusCancelled SubscriptionStatus = "cancelled" // Отменена, действует до end_date
+	StatusExpired   SubscriptionStatus = "expired"   // Завершена
+)
+
+// Статус открывает новый период активности подписки.
+// Отмена и завершение только ограничивают срок подписки датой окончания,
+// до которой продолжается текущий период
+func (s SubscriptionStatus) HasPeriod() bool {
+	switch s {
+	case StatusTrial, StatusActive, StatusPaused:
+		return true
+	}
+	return false
+}
+
+// Период в статусе не оплачивается
+func (s SubscriptionStatus) Free() bool {
+	return s == StatusTrial || s == StatusPaused
+}
+
+// Период активности подписки в одном статусе
+type StatusPeriod struct {
+	Status SubscriptionStatus `json:"status" example:"active"` // Статус периода: trial, active, paused
+	From   time.Time          `json:"from"`                    // Первый день периода
+	To     *time.Time         `json:"to,omitempty"`            // Первый день следующего периода, пустой у текущего
+}
+
+// Смена статуса подписки
+type StatusChange struct {
+	Status    SubscriptionStatus // Новый статус
+	At        time.Time          // Дата смены статуса, с нее начинается новый период
+	EndDate   *time.Time         // Новая дата окончания, nil - не меняется
+	IfVersion *int64             // Изменить, только если текущая версия совпадает
+}
@@ -9,26 +9,202 @@ import (
 var (
 	ErrSubscriptionNotFound = errors.New("подписка не найдена")
 	ErrInvalidPeriod        = errors.New("дана начала подписки должен быть раньше конца")
+	ErrInvalidBillingPeriod = errors.New("неизвестный расчетный период")
+	ErrInvalidCurrency      = errors.New("неверный код валюты")
+	ErrExchangeRateNotFound = errors.New("курс валюты не найден")
+	ErrInvalidCursor        = errors.New("неверный курсор пагинации")
+	ErrInvalidSort          = errors.New("неверное поле сортировки")
+	ErrInvalidLimit         = errors.New("неверный размер страницы")
+	ErrVersionMismatch      = errors.New("версия подписки не совпадает с ожидаемой")
+	ErrEffectiveFromNoPrice = errors.New("месяц начала действия цены задан без цены")
+	ErrInvalidProration     = errors.New("неизвестный режим пропорционального расчета")
 	ErrInternal             = errors.New("внутренняя ошибка сервера")
 )
 
-// Структура для создания подписки
+// Расчетный период подписки
+type BillingPeriod string
+
+// Расчетные периоды
+const (
+	BillingWeekly    BillingPeriod = "weekly"
+	BillingMonthly   BillingPeriod = "monthly"
+	BillingQuarterly BillingPeriod = "quarterly"
+	BillingYearly    BillingPeriod = "yearly"
+)
+
+// Проверка, что расчетный период поддерживается
+func (p BillingPeriod) Valid() bool {
+	switch p {
+	case BillingWeekly, BillingMonthly, BillingQuarterly, BillingYearly:
+		return true
+	}
+	return false
+}
+
+// Дата n-го списания подписки, начавшейся start (n = 0 - сама start).
+// Считается от даты начала, а не от предыдущего списания, поэтому день месяца не сдвигается:
+// подписка с 31 января списывается 28 февраля, 31 марта, 30 апреля.
+// Как при сложении с interval в PostgreSQL, день месяца не выходит за последний день месяца
+func (p BillingPeriod) Charge(start time.Time, n int) time.Time {
+	switch p {
+	case BillingWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case BillingQuarterly:
+		return addMonths(start, 3*n)
+	case BillingYearly:
+		return addMonths(start, 12*n)
+	}
+	return addMonths(start, n)
+}
+
+// Прибавление месяцев к дате. День ограничивается последним днем получившегося месяца
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return time.Date(first.Year(), first.Month(), min(t.Day(), last), 0, 0, 0, 0, t.Location())
+}
+
+// Валюта по умолчанию
+const DefaultCurrency = "RUB"
+
+// Проверка кода валюты по формату ISO 4217: три заглавные латинские буквы
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Структура подписки
 type Subscription struct {
-	ServiceName string     `json:"service_name"`       // Название сервиса
-	Price       int        `json:"price"`              // Цена в рублях
-	UserID      string     `json:"user_id"`            // UUID пользователя
-	StartDate   time.Time  `json:"start_date"`         // Дата начала
-	EndDate     *time.Time `json:"end_date,omitempty"` // Дата окончания
+	ID            string             `json:"id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"` // ID подписки
+	ServiceName   string             `json:"service_name"`                                      // Название сервиса
+	Price         int                `json:"price"`                                             // Цена за расчетный период по последней записи истории цен
+	Currency      string             `json:"currency" example:"RUB"`                            // Валюта подписки (ISO 4217)
+	BillingPeriod BillingPeriod      `json:"billing_period" example:"monthly"`                  // Расчетный период: weekly, monthly, quarterly, yearly
+	UserID        string             `json:"user_id"`                                           // UUID пользователя
+	StartDate     time.Time          `json:"start_date"`                                        // Дата начала, она же дата первого списания
+	EndDate       *time.Time         `json:"end_date,omitempty"`                                // Дата окончания, включительно
+	Status        SubscriptionStatus `json:"status" example:"active"`                           // Статус: trial, active, paused, cancelled, expired
+	TrialEndDate  *time.Time         `json:"trial_end_date,omitempty"`                          // Последний день пробного периода, включительно
+	CreatedAt     time.Time          `json:"created_at"`                                        // Время создания
+	UpdatedAt     time.Time          `json:"updated_at"`                                        // Время последнего изменения
+	Version       int64              `json:"version" example:"1"`                               // Версия, увеличивается при каждом изменении
+	DeletedAt     *time.Time         `json:"deleted_at,omitempty"`                              // Время удаления в корзину
+}
+
+// Цена подписки, действующая с даты EffectiveFrom до даты следующей записи
+type SubscriptionPrice struct {
+	Price         int       `json:"price"`          // Цена за расчетный период в валюте подписки
+	EffectiveFrom time.Time `json:"effective_from"` // Дата, с которой действует цена
+}
+
+// Поля сортировки списка подписок
+const (
+	SortByStartDate   = "start_date"
+	SortByPrice       = "price"
+	SortByServiceName = "service_name"
+)
+
+// Параметры получения списка подписок
+type ListSubscriptionsFilter struct {
+	UserID      string             // UUID пользователя
+	ServiceName string             // Название сервиса, пустое - все сервисы
+	ActiveFrom  *time.Time         // Подписка активна хотя бы один день периода с ActiveFrom по ActiveTo
+	ActiveTo    *time.Time         // Последний день периода активности, включительно
+	PriceMin    *int64             // Минимальная цена, включительно
+	PriceMax    *int64             // Максимальная цена, включительно
+	HasEndDate  *bool              // Наличие даты окончания
+	Status      SubscriptionStatus // Статус, пустой - любой
+	Deleted     bool               // Подписки из корзины вместо активных
+	SortBy      string             // Поле сортировки
+	SortDesc    bool               // Сортировка по убыванию
+	Limit       int                // Размер страницы
+	Cursor      string             // Курсор из next_cursor предыдущей страницы
+}
+
+// Страница списка подписок
+type SubscriptionPage struct {
+	Items      []Subscription `json:"items"`                                   // Подписки страницы
+	NextCursor string         `json:"next_cursor,omitempty" example:"eyJz..."` // Курсор следующей страницы, пустой на последней странице
 }
 
 // Структура для обновления подписки
 type UpdateSubscriptionInput struct {
-	Price   *int64     // Цена в рублях
-	EndDate *time.Time // Дата окончания
+	ServiceName   *string        // Название сервиса
+	Price         *int64         // Цена за расчетный период
+	Currency      *string        // Валюта (ISO 4217)
+	BillingPeriod *BillingPeriod // Расчетный период
+	StartDate     *time.Time     // Дата начала
+	EndDate       *time.Time     // Дата окончания
+	ClearEndDate  bool           // Сбросить дату окончания (бессрочная подписка)
+	EffectiveFrom *time.Time     // Дата, с которой действует новая цена
+	IfVersion     *int64         // Изменить, только если текущая версия совпадает
+}
+
+// Режим пропорционального расчета стоимости в отчетах
+type Proration string
+
+// Режимы пропорционального расчета
+const (
+	ProrationNone  Proration = "none"  // Каждое списание учитывается целиком в месяце своей даты
+	ProrationDaily Proration = "daily" // Цена расчетного периода распределяется по дням активности
+)
+
+// Проверка, что режим пропорционального расчета поддерживается
+func (p Proration) Valid() bool {
+	switch p {
+	case ProrationNone, ProrationDaily:
+		return true
+	}
+	return false
+}
+
+// Параметры отчетов о стоимости подписок
+type CostReportFilter struct {
+	UserID      string    // UUID пользователя
+	ServiceName string    // Название сервиса, пустое - все сервисы
+	StartDate   time.Time // Первый день периода
+	EndDate     time.Time // Последний день периода, включительно
+	Currency    string    // Валюта отчета (ISO 4217)
+	Proration   Proration // Режим пропорционального расчета
+}
+
+// Списание по подписке за один расчетный период
+type Charge struct {
+	SubscriptionID string         // ID подписки
+	ServiceName    string         // Название сервиса
+	PeriodStart    time.Time      // Дата списания, первый день расчетного периода
+	PeriodEnd      time.Time      // Первый день следующего расчетного периода
+	EndDate        *time.Time     // Дата окончания подписки, включительно
+	Amount         float64        // Сумма списания в валюте отчета
+	FreePeriods    []StatusPeriod // Неоплачиваемые периоды подписки: пробный период и пауза
+}
+
+// Стоимость подписок сервиса за месяц
+type MonthlyServiceCost struct {
+	Month       time.Time // Первый день месяца
+	ServiceName string    // Название сервиса
+	Cost        float64   // Сумма в валюте отчета без округления
+}
+
+// Строка разбивки стоимости по сервису
+type ServiceCostRow struct {
+	ServiceName string `json:"service_name" example:"Yandex Plus"` // Название сервиса
+	Costs       []int  `json:"costs"`                              // Стоимость по месяцам, в порядке поля months
+	Total       int    `json:"total" example:"3600"`               // Итог по сервису за период
 }
 
-// Структура ответа при ошибке
-type ErrorResponse struct {
-	Error   string `json:"error" example:"invalid input"`                 // Краткий код или сообщение
-	Details string `json:"details,omitempty" example:"email is required"` // (Опционально) Детали
+// Разбивка стоимости подписок по месяцам и сервисам
+type CostBreakdown struct {
+	Months      []time.Time      `json:"months"`                 // Первые дни месяцев периода
+	Services    []ServiceCostRow `json:"services"`               // Строки матрицы по сервисам
+	MonthTotals []int            `json:"month_totals"`           // Итоги по месяцам, в порядке поля months
+	Total       int              `json:"total" example:"7200"`   // Итог за период
+	Currency    string           `json:"currency" example:"RUB"` // Валюта отчета
 }
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// Ошибки
+var (
+	ErrTenantRequired = errors.New("организация запроса не определена")
+	ErrTenantMismatch = errors.New("организация из заголовка не совпадает с организацией пользователя")
+)
+
+// Ключ организации в контексте
+type tenantKey struct{}
+
+// Контекст с организацией, в пределах которой выполняется запрос
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// Организация из контекста, пустая строка - не определена
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantKey{}).(string)
+	return tenantID
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Структура создания API ключа
+type createAPIKeyInput struct {
+	Name   string   `json:"name" binding:"required,max=255" example:"billing-batch"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=subscriptions:read subscriptions:write reports:read" example:"subscriptions:read,reports:read"`
+}
+
+// CreateAPIKey - создание API ключа
+//
+//	@Summary		Создание API ключа
+//	@Description	Создать API ключ для межсервисных вызовов от имени текущего пользователя.
+//	@Description	Ключ передается в заголовке "Authorization: ApiKey <key>" и возвращается только в этом ответе
+//	@Tags			api-keys
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		createAPIKeyInput		true	"Данные API ключа"
+//	@Success		201		{object}	domain.IssuedAPIKey		"Созданный ключ"
+//	@Failure		400		{object}	domain.Problem	"Неверное тело запроса"
+//	@Failure		401		{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403		{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		429		{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500		{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Router			/api-keys [post]
+func (h *Handler) createAPIKey(c *gin.Context) {
+	var input createAPIKeyInput
+
+	// Читаем JSON
+	if err := c.ShouldBindJSON(&input); err != nil {
+		h.fail(c, bindingError(err, errInvalidBody))
+		return
+	}
+
+	scopes := make([]domain.Scope, len(input.Scopes))
+	for i, scope := range input.Scopes {
+		scopes[i] = domain.Scope(scope)
+	}
+
+	// Вызываем слой сервис
+	key, err := h.apiKeys.Create(c.Request.Context(), h.principal(c), input.Name, scopes)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	h.log.Info("создан API ключ", slog.String("api_key_id", key.ID), slog.String("user_id", key.UserID))
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// GetAPIKeys - список API ключей
+//
+//	@Summary		Список API ключей
+//	@Description	Получить API ключи текущего пользователя, включая отозванные. Сами ключи не возвращаются
+//	@Tags			api-keys
+//	@Produce		json
+//	@Success		200	{array}		domain.APIKey
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Router			/api-keys [get]
+func (h *Handler) getAPIKeys(c *gin.Context) {
+	// Вызываем слой сервис
+	keys, err := h.apiKeys.List(c.Request.Context(), h.principal(c).UserID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RotateAPIKey - смена секрета API ключа
+//
+//	@Summary		Смена секрета API ключа
+//	@Description	Выдать новый ключ вместо старого с теми же областями доступа. Старый ключ сразу перестает действовать
+//	@Tags			api-keys
+//	@Produce		json
+//	@Param			id	path		string	true	"ID API ключа"
+//	@Success		200	{object}	domain.IssuedAPIKey		"Новый ключ"
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404	{object}	domain.Problem	"API ключ не найден или отозван"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Router			/api-keys/{id}/rotate [post]
+func (h *Handler) rotateAPIKey(c *gin.Context) {
+	// Достаем id из URL
+	id := c.Param("id")
+
+	// Вызываем слой сервис
+	key, err := h.apiKeys.Rotate(c.Request.Context(), h.principal(c).UserID, id)
+	if err != nil {
+		h.fail(c, err, slog.String("api_key_id", id))
+		return
+	}
+
+	h.log.Info("секрет API ключа изменен", slog.String("api_key_id", key.ID))
+
+	c.JSON(http.StatusOK, key)
+}
+
+// RevokeAPIKey - отзыв API ключа
+//
+//	@Summary		Отзыв API ключа
+//	@Description	Отозвать API ключ. Отозванный ключ перестает действовать и не может быть восстановлен
+//	@Tags			api-keys
+//	@Param			id	path	string	true	"ID API ключа"
+//	@Success		204	"API ключ отозван"
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404	{object}	domain.Problem	"API ключ не найден или уже отозван"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Router			/api-keys/{id} [delete]
+func (h *Handler) revokeAPIKey(c *gin.Context) {
+	// Достаем id из URL
+	id := c.Param("id")
+
+	// Вызываем слой сервис
+	if err := h.apiKeys.Revoke(c.Request.Context(), h.principal(c).UserID, id); err != nil {
+		h.fail(c, err, slog.String("api_key_id", id))
+		return
+	}
+
+	h.log.Info("API ключ отозван", slog.String("api_key_id", id))
+
+	c.Status(http.StatusNoContent)
+}
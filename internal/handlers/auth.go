@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware аутентификации по заголовку Authorization: Bearer <JWT> или ApiKey <ключ>.
+// Пользователь попадает в контекст запроса и в историю изменений подписок
+func (h *Handler) authenticate(c *gin.Context) {
+	scheme, credentials, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+
+	var (
+		principal domain.Principal
+		err       error
+	)
+
+	switch {
+	case scheme == "Bearer" && credentials != "":
+		principal, err = h.tokens.Verify(credentials)
+	case scheme == "ApiKey" && credentials != "":
+		principal, err = h.apiKeys.Authenticate(c.Request.Context(), credentials)
+	default:
+		c.Header("WWW-Authenticate", `Bearer, ApiKey`)
+		h.fail(c, errNoCredentials)
+		return
+	}
+
+	if err != nil {
+		if errors.Is(err, domain.ErrUnauthorized) || errors.Is(err, domain.ErrInvalidAPIKey) {
+			c.Header("WWW-Authenticate", scheme+` error="invalid_token"`)
+		}
+		h.fail(c, err, slog.String("scheme", scheme))
+		return
+	}
+
+	tenantID, err := h.resolveTenant(c, principal)
+	if err != nil {
+		h.fail(c, err, slog.String("user_id", principal.UserID), slog.String("tenant_id", principal.TenantID))
+		return
+	}
+	principal.TenantID = tenantID
+
+	ctx := domain.WithPrincipal(c.Request.Context(), principal)
+	ctx = domain.WithActor(ctx, principal.UserID)
+	ctx = domain.WithTenant(ctx, tenantID)
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+// Организация запроса.
+// Организация берется из токена или API ключа, а если в учетных данных ее нет - организация по умолчанию.
+// Заголовок выбирает организацию только в доверенном развертывании, иначе он должен совпадать
+// с организацией из учетных данных
+func (h *Handler) resolveTenant(c *gin.Context, principal domain.Principal) (string, error) {
+	header := c.GetHeader(h.tenant.header)
+
+	tenantID := principal.TenantID
+	if tenantID == "" && h.tenant.trustHeader {
+		tenantID = header
+	}
+	if tenantID == "" {
+		tenantID = h.tenant.fallback
+	}
+
+	if tenantID == "" {
+		return "", domain.ErrTenantRequired
+	}
+	if header != "" && header != tenantID {
+		return "", domain.ErrTenantMismatch
+	}
+
+	return tenantID, nil
+}
+
+// Middleware, пропускающее только администраторов
+func (h *Handler) requireAdmin(c *gin.Context) {
+	principal := h.principal(c)
+	if !principal.IsAdmin() {
+		h.fail(c, domain.ErrForbidden, slog.String("user_id", principal.UserID))
+		return
+	}
+
+	c.Next()
+}
+
+// Middleware, пропускающее API ключи только с областью доступа scope
+func (h *Handler) requireScope(scope domain.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := h.principal(c)
+		if !principal.HasScope(scope) {
+			h.fail(c, withDetail(errMissingScope, "scope_required", scope), slog.String("api_key_id", principal.APIKeyID))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Middleware, запрещающее вход по API ключу.
+// Управление ключами и администрирование доступны только по JWT
+func (h *Handler) denyAPIKeys(c *gin.Context) {
+	principal := h.principal(c)
+	if principal.IsAPIKey() {
+		h.fail(c, errAPIKeyNotAllowed, slog.String("api_key_id", principal.APIKeyID))
+		return
+	}
+
+	c.Next()
+}
+
+// Аутентифицированный пользователь запроса
+func (h *Handler) principal(c *gin.Context) domain.Principal {
+	principal, _ := domain.PrincipalFromContext(c.Request.Context())
+	return principal
+}
+
+// Пользователь, данные которого запрошены: из параметра user_id,
+// а если он не указан - сам аутентифицированный пользователь. UUID сравниваются в нижнем регистре
+func (h *Handler) requestedUser(c *gin.Context) string {
+	userID := strings.ToLower(c.Query("user_id"))
+	if userID == "" {
+		userID = h.principal(c).UserID
+	}
+
+	return userID
+}
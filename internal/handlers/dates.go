@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Форматы дат во входных данных
+const (
+	dayLayout   = "2006-01-02" // День: YYYY-MM-DD
+	monthLayout = "01-2006"    // Месяц: MM-YYYY
+)
+
+// Принимаемые форматы дат для сообщений об ошибках
+const dateFormats = "YYYY-MM-DD MM-YYYY RFC3339"
+
+// Разбор даты в формате YYYY-MM-DD, MM-YYYY или момента времени RFC 3339.
+// Момент времени переводится в дату по часовому поясу loc.
+// Для месяца возвращается его первый день и month = true
+func parseDate(dateStr string, loc *time.Location) (t time.Time, month bool, err error) {
+	if t, err := time.Parse(dayLayout, dateStr); err == nil {
+		return t, false, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, dateStr); err == nil {
+		t = t.In(loc)
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), false, nil
+	}
+
+	t, err = time.Parse(monthLayout, dateStr)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
+
+// Последний день месяца, которому принадлежит дата
+func lastDayOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
+
+// Формат дат в ответе
+type dateFormat string
+
+// Форматы дат в ответе
+const (
+	dateFormatDay      dateFormat = "date"     // YYYY-MM-DD, месяцы - YYYY-MM
+	dateFormatMonth    dateFormat = "month"    // MM-YYYY, для дней тоже
+	dateFormatDateTime dateFormat = "datetime" // RFC 3339, полночь в часовом поясе запроса
+)
+
+// Ключ формата дат в контексте gin
+const dateFormatKey = "date_format"
+
+// Middleware выбора формата дат ответа по параметру date_format.
+// Формат один для всех дат ответа, по умолчанию - YYYY-MM-DD
+func (h *Handler) dateFormatting(c *gin.Context) {
+	format := dateFormat(c.DefaultQuery("date_format", string(dateFormatDay)))
+
+	switch format {
+	case dateFormatDay, dateFormatMonth, dateFormatDateTime:
+	default:
+		h.fail(c, fieldError("date_format", "oneof", "date month datetime"))
+		return
+	}
+
+	c.Set(dateFormatKey, format)
+	c.Next()
+}
+
+// Представление дат и времени в ответе: формат дат и часовой пояс запроса
+type dateFormatter struct {
+	format dateFormat
+	loc    *time.Location
+}
+
+// Представление дат ответа на запрос
+func dateFormatOf(c *gin.Context) dateFormatter {
+	f := dateFormatter{format: dateFormatDay, loc: domain.LocationFromContext(c.Request.Context())}
+	if format, ok := c.Get(dateFormatKey); ok {
+		f.format = format.(dateFormat)
+	}
+	return f
+}
+
+// Дата в формате ответа
+func (f dateFormatter) date(t time.Time) string {
+	switch f.format {
+	case dateFormatMonth:
+		return t.Format(monthLayout)
+	case dateFormatDateTime:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, f.loc).Format(time.RFC3339)
+	}
+	return t.Format(dayLayout)
+}
+
+// Момент времени в часовом поясе запроса
+func (f dateFormatter) instant(t time.Time) time.Time {
+	return t.In(f.loc)
+}
+
+// Необязательный момент времени в часовом поясе запроса
+func (f dateFormatter) optionalInstant(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	in := f.instant(*t)
+	return &in
+}
+
+// Необязательная дата в формате ответа
+func (f dateFormatter) optionalDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	s := f.date(*t)
+	return &s
+}
+
+// Месяц в формате ответа. t - первый день месяца
+func (f dateFormatter) month(t time.Time) string {
+	if f.format == dateFormatDay {
+		return t.Format("2006-01")
+	}
+	return f.date(t)
+}
+
+// Подписка в ответе
+type subscriptionView struct {
+	domain.Subscription
+	StartDate    string     `json:"start_date" example:"2025-07-15"`               // Дата начала, она же дата первого списания
+	EndDate      *string    `json:"end_date,omitempty" example:"2025-12-31"`       // Дата окончания, включительно
+	TrialEndDate *string    `json:"trial_end_date,omitempty" example:"2025-07-29"` // Последний день пробного периода, включительно
+	CreatedAt    time.Time  `json:"created_at"`                                    // Время создания
+	UpdatedAt    time.Time  `json:"updated_at"`                                    // Время последнего изменения
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`                          // Время удаления в корзину
+}
+
+// Представление подписки с датами в формате ответа
+func (f dateFormatter) subscription(sub domain.Subscription) subscriptionView {
+	return subscriptionView{
+		Subscription: sub,
+		StartDate:    f.date(sub.StartDate),
+		EndDate:      f.optionalDate(sub.EndDate),
+		TrialEndDate: f.optionalDate(sub.TrialEndDate),
+		CreatedAt:    f.instant(sub.CreatedAt),
+		UpdatedAt:    f.instant(sub.UpdatedAt),
+		DeletedAt:    f.optionalInstant(sub.DeletedAt),
+	}
+}
+
+// Страница списка подписок в ответе
+type subscriptionPageView struct {
+	domain.SubscriptionPage
+	Items []subscriptionView `json:"items"` // Подписки страницы
+}
+
+// Представление страницы списка с датами в формате ответа
+func (f dateFormatter) subscriptionPage(page domain.SubscriptionPage) subscriptionPageView {
+	items := make([]subscriptionView, 0, len(page.Items))
+	for _, sub := range page.Items {
+		items = append(items, f.subscription(sub))
+	}
+
+	return subscriptionPageView{SubscriptionPage: page, Items: items}
+}
+
+// Запись истории изменений подписки в ответе
+type subscriptionEventView struct {
+	domain.SubscriptionEvent
+	Before    *subscriptionView `json:"before,omitempty"` // Состояние до изменения
+	After     *subscriptionView `json:"after,omitempty"`  // Состояние после изменения
+	CreatedAt time.Time         `json:"created_at"`       // Время изменения
+}
+
+// Представление истории изменений с датами в формате ответа
+func (f dateFormatter) subscriptionEvents(events []domain.SubscriptionEvent) []subscriptionEventView {
+	views := make([]subscriptionEventView, 0, len(events))
+	for _, event := range events {
+		view := subscriptionEventView{SubscriptionEvent: event, CreatedAt: f.instant(event.CreatedAt)}
+		if event.Before != nil {
+			before := f.subscription(*event.Before)
+			view.Before = &before
+		}
+		if event.After != nil {
+			after := f.subscription(*event.After)
+			view.After = &after
+		}
+		views = append(views, view)
+	}
+
+	return views
+}
+
+// Цена подписки в ответе
+type subscriptionPriceView struct {
+	domain.SubscriptionPrice
+	EffectiveFrom string `json:"effective_from" example:"2025-09-01"` // Дата, с которой действует цена
+}
+
+// Представление истории цен с датами в формате ответа
+func (f dateFormatter) subscriptionPrices(prices []domain.SubscriptionPrice) []subscriptionPriceView {
+	views := make([]subscriptionPriceView, 0, len(prices))
+	for _, price := range prices {
+		views = append(views, subscriptionPriceView{
+			SubscriptionPrice: price,
+			EffectiveFrom:     f.date(price.EffectiveFrom),
+		})
+	}
+
+	return views
+}
+
+// Разбивка стоимости в ответе
+type costBreakdownView struct {
+	domain.CostBreakdown
+	Months []string `json:"months" example:"2025-01,2025-02"` // Месяцы периода
+}
+
+// Представление разбивки стоимости с месяцами в формате ответа
+func (f dateFormatter) costBreakdown(breakdown domain.CostBreakdown) costBreakdownView {
+	months := make([]string, 0, len(breakdown.Months))
+	for _, m := range breakdown.Months {
+		months = append(months, f.month(m))
+	}
+
+	return costBreakdownView{CostBreakdown: breakdown, Months: months}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Установка заголовка ETag по версии подписки
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// Чтение ожидаемой версии подписки из заголовка If-Match.
+//
+// Без заголовка и для "*" условие не проверяется и возвращается nil.
+// Слабые ETag и несколько значений не могут совпасть при строгом сравнении,
+// поэтому для них сразу отправляется 412 и возвращается false
+func (h *Handler) ifMatchVersion(c *gin.Context) (*int64, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, true
+	}
+
+	if len(header) >= 2 && strings.HasPrefix(header, `"`) && strings.HasSuffix(header, `"`) {
+		version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+		if err == nil {
+			return &version, true
+		}
+	}
+
+	h.fail(c, withDetail(domain.ErrVersionMismatch, "if_match_unsupported"), slog.String("if_match", header))
+	return nil, false
+}
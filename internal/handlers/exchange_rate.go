@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Максимальный размер загружаемого файла курсов
+const maxRatesFileSize = 5 << 20
+
+// GetExchangeRates - получение загруженных курсов валют
+//
+//	@Summary		Получение курсов валют
+//	@Description	Получить курсы валют, по которым пересчитывается стоимость подписок в отчетах
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{array}		domain.ExchangeRate
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Router			/admin/exchange-rates [get]
+func (h *Handler) getExchangeRates(c *gin.Context) {
+	// Вызываем слой сервис
+	rates, err := h.rates.List(c.Request.Context())
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// LoadExchangeRates - загрузка курсов валют из файла
+//
+//	@Summary		Загрузка курсов валют
+//	@Description	Загрузить курсы валют из файла и заменить ими текущие курсы организации. Тело запроса - содержимое файла.
+//	@Description	Поддерживается XML в формате ЕЦБ (eurofxref, базовая валюта EUR) и CSV со строками вида currency,rate,
+//	@Description	где rate - количество единиц валюты за единицу базовой валюты из параметра base
+//	@Tags			admin
+//	@Accept			xml
+//	@Accept			plain
+//	@Produce		json
+//	@Param			format	query		string	false	"Формат файла: ecb или csv. По умолчанию определяется по Content-Type"	Enums(ecb, csv)
+//	@Param			base	query		string	false	"Базовая валюта курсов из CSV файла"	default(RUB)
+//	@Success		200		{array}		domain.ExchangeRate
+//	@Failure		400		{object}	domain.Problem	"Неверный файл курсов"
+//	@Failure		401		{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403		{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		429		{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500		{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Router			/admin/exchange-rates [post]
+func (h *Handler) loadExchangeRates(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		format = ratesFormatFromContentType(c.ContentType())
+	}
+	base := strings.ToUpper(c.DefaultQuery("base", domain.DefaultCurrency))
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxRatesFileSize)
+
+	// Вызываем слой сервис
+	rates, err := h.rates.Load(c.Request.Context(), format, base, body)
+	if err != nil {
+		// Ошибка разбора файла указывает клиенту на неверную строку или значение
+		if errors.Is(err, domain.ErrInvalidRatesFile) || errors.Is(err, domain.ErrInvalidCurrency) {
+			err = withDetail(err, "rates_file", err.Error())
+		}
+		h.fail(c, err, slog.String("format", format))
+		return
+	}
+
+	h.log.Info("курсы валют загружены", slog.String("format", format), slog.Int("count", len(rates)))
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// Определение формата файла курсов по Content-Type
+func ratesFormatFromContentType(contentType string) string {
+	switch contentType {
+	case "application/xml", "text/xml":
+		return domain.RatesFormatECB
+	case "text/csv", "text/plain":
+		return domain.RatesFormatCSV
+	}
+	return ""
+}
@@ -2,10 +2,11 @@ package handlers
 
 import (
 	"context"
+	"io"
 	"log/slog"
-	"time"
 
 	"github.com/levinOo/go-crudl-task/internal/domain"
+	"github.com/levinOo/go-crudl-task/pkg/ratelimit"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -16,45 +17,158 @@ import (
 
 // Интерфейс сервиса подписок
 type SubscriptionService interface {
-	Create(ctx context.Context, sub domain.Subscription) (string, error)
+	AuthorizeCreate(ctx context.Context, sub domain.Subscription) error
+	Create(ctx context.Context, sub domain.Subscription) (domain.Subscription, error)
 	Get(ctx context.Context, id string) (domain.Subscription, error)
-	Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) error
-	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, userID string) ([]domain.Subscription, error)
-	GetTotalCost(ctx context.Context, userID, serviceName string, startDate, endDate time.Time) (int, error)
+	Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) (domain.Subscription, error)
+	Replace(ctx context.Context, id string, sub domain.Subscription, ifVersion *int64) (domain.Subscription, error)
+	Delete(ctx context.Context, id string, ifVersion *int64) error
+	List(ctx context.Context, filter domain.ListSubscriptionsFilter) (domain.SubscriptionPage, error)
+	Restore(ctx context.Context, id string) (domain.Subscription, error)
+	ChangeStatus(ctx context.Context, id string, action domain.SubscriptionAction, ifVersion *int64) (domain.Subscription, error)
+	Purge(ctx context.Context) (int64, error)
+	History(ctx context.Context, id string) ([]domain.SubscriptionEvent, error)
+	Prices(ctx context.Context, id string) ([]domain.SubscriptionPrice, error)
+	GetTotalCost(ctx context.Context, filter domain.CostReportFilter) (int, error)
+	GetCostBreakdown(ctx context.Context, filter domain.CostReportFilter) (domain.CostBreakdown, error)
+}
+
+// Интерфейс сервиса курсов валют
+type ExchangeRateService interface {
+	Load(ctx context.Context, format, base string, data io.Reader) ([]domain.ExchangeRate, error)
+	List(ctx context.Context) ([]domain.ExchangeRate, error)
+}
+
+// Интерфейс сервиса ключей идемпотентности
+type IdempotencyService interface {
+	Begin(ctx context.Context, userID, key, requestHash string) (*domain.IdempotencyRecord, error)
+	Complete(ctx context.Context, userID, key string, fn func(ctx context.Context) (int, []byte, error)) error
+}
+
+// Интерфейс сервиса API ключей
+type APIKeyService interface {
+	Create(ctx context.Context, owner domain.Principal, name string, scopes []domain.Scope) (domain.IssuedAPIKey, error)
+	List(ctx context.Context, userID string) ([]domain.APIKey, error)
+	Rotate(ctx context.Context, userID, id string) (domain.IssuedAPIKey, error)
+	Revoke(ctx context.Context, userID, id string) error
+	Authenticate(ctx context.Context, rawKey string) (domain.Principal, error)
+}
+
+// Интерфейс проверки токенов доступа
+type TokenVerifier interface {
+	Verify(token string) (domain.Principal, error)
+}
+
+// Структура зависимостей хендлера
+type Deps struct {
+	Subscriptions SubscriptionService
+	ExchangeRates ExchangeRateService
+	Idempotency   IdempotencyService
+	APIKeys       APIKeyService
+	Tokens        TokenVerifier
+	TenantHeader  string // Заголовок с организацией запроса
+	DefaultTenant string // Организация, если ее нет ни в токене, ни в заголовке
+	TrustTenant   bool   // Заголовок может выбрать организацию: его выставляет доверенный шлюз
+	RateLimiter   ratelimit.Store
+	RateLimits    RateLimits
+	Log           *slog.Logger
 }
 
 // Структура хендлера
 type Handler struct {
-	services SubscriptionService
-	log      *slog.Logger
+	services    SubscriptionService
+	rates       ExchangeRateService
+	idempotency IdempotencyService
+	apiKeys     APIKeyService
+	tokens      TokenVerifier
+	tenant      tenantConfig
+	limiter     ratelimit.Store
+	limits      RateLimits
+	log         *slog.Logger
+}
+
+// Настройки определения организации запроса
+type tenantConfig struct {
+	header      string
+	fallback    string
+	trustHeader bool
 }
 
 // Создание нового хендлера
-func NewHandler(services SubscriptionService, log *slog.Logger) *Handler {
+func NewHandler(deps Deps) *Handler {
+	registerFieldNames()
+
 	return &Handler{
-		services: services,
-		log:      log,
+		services:    deps.Subscriptions,
+		rates:       deps.ExchangeRates,
+		idempotency: deps.Idempotency,
+		apiKeys:     deps.APIKeys,
+		tokens:      deps.Tokens,
+		tenant: tenantConfig{
+			header:      deps.TenantHeader,
+			fallback:    deps.DefaultTenant,
+			trustHeader: deps.TrustTenant,
+		},
+		limiter: deps.RateLimiter,
+		limits:  deps.RateLimits,
+		log:     deps.Log,
 	}
 }
 
 // Инициализация маршрутов
 func (h *Handler) InitRoutes(router *gin.Engine) {
-	router.Use(gin.Recovery())
+	router.Use(h.requestID, h.language, gin.CustomRecovery(h.recoverPanic), h.dateFormatting)
 
 	api := router.Group("/api")
 	{
 		v1 := api.Group("/v1")
+		v1.Use(h.rateLimit("auth", h.limits.Auth), h.authenticate, h.timeZone)
 		{
-			subs := v1.Group("/subscriptions")
+			read := h.requireScope(domain.ScopeSubscriptionsRead)
+			write := h.requireScope(domain.ScopeSubscriptionsWrite)
+			reports := h.requireScope(domain.ScopeReportsRead)
+
+			// Отчеты ограничиваются отдельно от остальных маршрутов подписок
+			subs := v1.Group("/subscriptions", h.rateLimit("subscriptions", h.limits.Subscriptions))
 			{
-				subs.POST("", h.createSubscription)
-				subs.GET("", h.getList)
+				subs.POST("", write, h.createSubscription)
+				subs.GET("", read, h.getList)
+				subs.GET("/trash", read, h.getTrash)
+
+				subs.GET("/:id", read, h.getSubscription)
+				subs.PUT("/:id", write, h.replaceSubscription)
+				subs.PATCH("/:id", write, h.updateSubscription)
+				subs.DELETE("/:id", write, h.deleteSubscription)
+				subs.POST("/:id/restore", write, h.restoreSubscription)
+				subs.POST("/:id/activate", write, h.activateSubscription)
+				subs.POST("/:id/pause", write, h.pauseSubscription)
+				subs.POST("/:id/resume", write, h.resumeSubscription)
+				subs.POST("/:id/cancel", write, h.cancelSubscription)
+				subs.GET("/:id/history", read, h.getHistory)
+				subs.GET("/:id/prices", read, h.getPrices)
+			}
 
-				subs.GET("/:id", h.getSubscription)
-				subs.PATCH("/:id", h.updateSubscription)
-				subs.DELETE("/:id", h.deleteSubscription)
-				subs.GET("/total-cost", h.getTotalCost)
+			costs := v1.Group("/subscriptions", h.rateLimit("reports", h.limits.Reports))
+			{
+				costs.GET("/total-cost", reports, h.getTotalCost)
+				costs.GET("/cost-breakdown", reports, h.getCostBreakdown)
+			}
+
+			keys := v1.Group("/api-keys")
+			keys.Use(h.denyAPIKeys, h.rateLimit("api_keys", h.limits.APIKeys))
+			{
+				keys.POST("", h.createAPIKey)
+				keys.GET("", h.getAPIKeys)
+				keys.POST("/:id/rotate", h.rotateAPIKey)
+				keys.DELETE("/:id", h.revokeAPIKey)
+			}
+
+			admin := v1.Group("/admin")
+			admin.Use(h.denyAPIKeys, h.requireAdmin, h.rateLimit("admin", h.limits.Admin))
+			{
+				admin.GET("/exchange-rates", h.getExchangeRates)
+				admin.POST("/exchange-rates", h.loadExchangeRates)
+				admin.POST("/subscriptions/purge", h.purgeSubscriptions)
 			}
 		}
 	}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Максимальная длина ключа идемпотентности
+const maxIdempotencyKeyLength = 255
+
+// Начало запроса с ключом идемпотентности. Ключ действует в пределах пользователя запроса.
+// Хеш считается по разобранному запросу input, поэтому пробелы и порядок полей JSON на него не влияют.
+//
+// Возвращает true, если запрос нужно выполнить. Иначе ответ уже отправлен:
+// сохраненный ответ для повтора или ошибка
+func (h *Handler) beginIdempotent(c *gin.Context, key string, input any) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		h.fail(c, errIdempotencyKeyTooLong, slog.Int("length", len(key)))
+		return false
+	}
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		h.fail(c, fmt.Errorf("Ошибка при сериализации запроса: %w", err))
+		return false
+	}
+	hash := sha256.Sum256(body)
+
+	record, err := h.idempotency.Begin(c.Request.Context(), h.principal(c).UserID, key, hex.EncodeToString(hash[:]))
+	if err != nil {
+		h.fail(c, err, slog.String("key", key))
+		return false
+	}
+
+	if record == nil {
+		return true
+	}
+
+	// Повтор: возвращаем исходный ответ
+	h.log.Info("повтор запроса с ключом идемпотентности", slog.String("key", key))
+	c.Header("Idempotent-Replayed", "true")
+
+	var created struct {
+		ID      string `json:"id"`
+		Version int64  `json:"version"`
+	}
+	if record.StatusCode == http.StatusCreated && json.Unmarshal(record.Response, &created) == nil {
+		h.writeCreated(c, created.ID, created.Version, record.Response)
+		return false
+	}
+
+	c.Data(record.StatusCode, "application/json; charset=utf-8", record.Response)
+	return false
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/levinOo/go-crudl-task/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware выбора языка ответа по заголовку Accept-Language.
+// Язык попадает в контекст запроса и в заголовок Content-Language
+func (h *Handler) language(c *gin.Context) {
+	lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+
+	c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), lang))
+	c.Header("Content-Language", lang.String())
+	c.Header("Vary", "Accept-Language")
+
+	c.Next()
+}
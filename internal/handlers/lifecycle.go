@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActivateSubscription - перевод пробного периода в оплачиваемый
+//
+//	@Summary		Активация подписки
+//	@Description	Перевести подписку из пробного периода в оплачиваемую с текущей даты
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			id			path		string	true	"ID подписки"
+//	@Param			If-Match	header		string	false	"ETag подписки из предыдущего ответа"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200	{object}	subscriptionView	"Подписка в новом статусе"
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404	{object}	domain.Problem	"Подписка не найдена"
+//	@Failure		409	{object}	domain.Problem	"Подписка не в пробном периоде"
+//	@Failure		412	{object}	domain.Problem	"Версия подписки не совпадает с If-Match"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/subscriptions/{id}/activate [post]
+func (h *Handler) activateSubscription(c *gin.Context) {
+	h.changeStatus(c, domain.ActionActivated)
+}
+
+// PauseSubscription - приостановка подписки
+//
+//	@Summary		Приостановка подписки
+//	@Description	Приостановить активную подписку с текущей даты. Дни паузы не оплачиваются
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			id			path		string	true	"ID подписки"
+//	@Param			If-Match	header		string	false	"ETag подписки из предыдущего ответа"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200	{object}	subscriptionView	"Подписка в новом статусе"
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404	{object}	domain.Problem	"Подписка не найдена"
+//	@Failure		409	{object}	domain.Problem	"Подписка не активна"
+//	@Failure		412	{object}	domain.Problem	"Версия подписки не совпадает с If-Match"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/subscriptions/{id}/pause [post]
+func (h *Handler) pauseSubscription(c *gin.Context) {
+	h.changeStatus(c, domain.ActionPaused)
+}
+
+// ResumeSubscription - возобновление подписки
+//
+//	@Summary		Возобновление подписки
+//	@Description	Возобновить приостановленную подписку с текущей даты
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			id			path		string	true	"ID подписки"
+//	@Param			If-Match	header		string	false	"ETag подписки из предыдущего ответа"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200	{object}	subscriptionView	"Подписка в новом статусе"
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404	{object}	domain.Problem	"Подписка не найдена"
+//	@Failure		409	{object}	domain.Problem	"Подписка не приостановлена"
+//	@Failure		412	{object}	domain.Problem	"Версия подписки не совпадает с If-Match"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/subscriptions/{id}/resume [post]
+func (h *Handler) resumeSubscription(c *gin.Context) {
+	h.changeStatus(c, domain.ActionResumed)
+}
+
+// CancelSubscription - отмена подписки
+//
+//	@Summary		Отмена подписки
+//	@Description	Отменить подписку. Активная подписка действует до конца оплаченного расчетного периода, пробная - до trial_end_date, если он задан, иначе, как и приостановленная, заканчивается сегодня. Более ранняя дата окончания сохраняется
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			id			path		string	true	"ID подписки"
+//	@Param			If-Match	header		string	false	"ETag подписки из предыдущего ответа"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200	{object}	subscriptionView	"Подписка в новом статусе"
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404	{object}	domain.Problem	"Подписка не найдена"
+//	@Failure		409	{object}	domain.Problem	"Подписка уже отменена или истекла"
+//	@Failure		412	{object}	domain.Problem	"Версия подписки не совпадает с If-Match"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/subscriptions/{id}/cancel [post]
+func (h *Handler) cancelSubscription(c *gin.Context) {
+	h.changeStatus(c, domain.ActionCancelled)
+}
+
+// Смена статуса подписки действием action
+func (h *Handler) changeStatus(c *gin.Context, action domain.SubscriptionAction) {
+	// Достаем id из URL
+	id := c.Param("id")
+	if id == "" {
+		h.fail(c, fieldError("id", "required", ""))
+		return
+	}
+
+	ifVersion, ok := h.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	// Вызываем слой сервис
+	sub, err := h.services.ChangeStatus(c.Request.Context(), id, action, ifVersion)
+	if err != nil {
+		h.fail(c, err, slog.String("id", id), slog.String("action", string(action)))
+		return
+	}
+
+	setETag(c, sub.Version)
+	c.JSON(http.StatusOK, dateFormatOf(c).subscription(sub))
+}
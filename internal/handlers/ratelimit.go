@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/levinOo/go-crudl-task/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// По чему считаются запросы клиента
+const (
+	RateLimitByIP     = "ip"     // IP адрес клиента
+	RateLimitByUser   = "user"   // Пользователь, все его API ключи делят один лимит
+	RateLimitByClient = "client" // API ключ, а при входе по JWT - пользователь
+)
+
+// Ошибки
+var ErrUnknownRateLimitKey = errors.New("неизвестный способ подсчета запросов")
+
+// Ограничение частоты запросов группы маршрутов
+type RateLimit struct {
+	Limit ratelimit.Limit
+	By    string
+}
+
+// Ограничения частоты запросов по группам маршрутов.
+// Auth действует на все запросы к /api/v1 до проверки учетных данных и считается только по IP,
+// чтобы подбор ключей и токенов тоже упирался в лимит
+type RateLimits struct {
+	Auth          RateLimit
+	Subscriptions RateLimit
+	Reports       RateLimit
+	APIKeys       RateLimit
+	Admin         RateLimit
+}
+
+// Проверка способов подсчета запросов
+func (l RateLimits) Validate() error {
+	for _, limit := range []RateLimit{l.Auth, l.Subscriptions, l.Reports, l.APIKeys, l.Admin} {
+		switch limit.By {
+		case RateLimitByIP, RateLimitByUser, RateLimitByClient:
+		default:
+			return fmt.Errorf("%w: %q", ErrUnknownRateLimitKey, limit.By)
+		}
+	}
+
+	return nil
+}
+
+// Middleware ограничения частоты запросов группы маршрутов group.
+// Ответ содержит заголовки RateLimit-*, при превышении лимита - 429 с Retry-After.
+// Если хранилище недоступно, запрос пропускается
+func (h *Handler) rateLimit(group string, limit RateLimit) gin.HandlerFunc {
+	if h.limiter == nil || !limit.Limit.Enabled() {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	policy := fmt.Sprintf("%d;w=%d", limit.Limit.Requests, ceilSeconds(limit.Limit.Period))
+
+	return func(c *gin.Context) {
+		key := group + ":" + h.rateLimitKey(c, limit.By)
+
+		result, err := h.limiter.Take(c.Request.Context(), key, limit.Limit)
+		if err != nil {
+			h.log.Error("ошибка при проверке лимита запросов", slog.String("group", group), slog.String("error", err.Error()))
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			h.fail(c, errTooManyRequests, slog.String("group", group), slog.String("key", key))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Ключ клиента, по которому считаются запросы.
+// Пользователи разных организаций считаются отдельно
+func (h *Handler) rateLimitKey(c *gin.Context, by string) string {
+	principal := h.principal(c)
+
+	switch {
+	case by == RateLimitByClient && principal.IsAPIKey():
+		return "key:" + principal.APIKeyID
+	case by != RateLimitByIP && principal.UserID != "":
+		return "user:" + principal.TenantID + "/" + principal.UserID
+	default:
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// Длительность в целых секундах с округлением вверх
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
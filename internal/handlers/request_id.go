@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"crypto/rand"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Заголовок с ID запроса
+const requestIDHeader = "X-Request-ID"
+
+// Ключ ID запроса в контексте gin
+const requestIDKey = "request_id"
+
+// Максимальная длина ID запроса, принимаемого от клиента
+const maxRequestIDLength = 128
+
+// Middleware ID запроса.
+// ID берется из заголовка X-Request-ID, если клиент его передал, иначе генерируется.
+// ID возвращается в том же заголовке и попадает в поле instance ответов об ошибках
+func (h *Handler) requestID(c *gin.Context) {
+	id := c.GetHeader(requestIDHeader)
+	if !validRequestID(id) {
+		id = rand.Text()
+	}
+
+	c.Set(requestIDKey, id)
+	c.Header(requestIDHeader, id)
+
+	c.Next()
+}
+
+// ID текущего запроса
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// ID от клиента допустим, если он не длиннее maxRequestIDLength
+// и состоит из букв латиницы, цифр и символов - _ . :
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
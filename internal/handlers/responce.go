@@ -1,15 +1,162 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
 	"github.com/levinOo/go-crudl-task/internal/domain"
+	"github.com/levinOo/go-crudl-task/internal/i18n"
 
 	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// Префикс поля type ответа об ошибке
+const problemTypePrefix = "urn:problem:"
+
+// Ошибки уровня HTTP, не относящиеся к предметной области
+var (
+	errNoCredentials         = errors.New("учетные данные не переданы")
+	errInvalidBody           = errors.New("неверное тело запроса")
+	errInvalidQuery          = errors.New("неверные параметры запроса")
+	errMissingScope          = errors.New("у API ключа нет области доступа")
+	errAPIKeyNotAllowed      = errors.New("маршрут недоступен по API ключу")
+	errTooManyRequests       = errors.New("слишком много запросов")
+	errIdempotencyKeyTooLong = errors.New("ключ идемпотентности длиннее 255 символов")
 )
 
-// newErrorResponse отправляет ошибку в JSON и логирует её (опционально)
-func newErrorResponse(c *gin.Context, statusCode int, message string) {
+// Вид ошибки API: HTTP статус и стабильный код.
+// Заголовок ошибки берется из каталога сообщений по коду
+type problemSpec struct {
+	status int
+	code   string
+}
+
+// Соответствие ошибок видам ошибок API.
+// Ошибка сравнивается через errors.Is по порядку, не найденные ошибки считаются внутренними
+var problemSpecs = []struct {
+	err  error
+	spec problemSpec
+}{
+	{errNoCredentials, problemSpec{http.StatusUnauthorized, "unauthenticated"}},
+	{domain.ErrUnauthorized, problemSpec{http.StatusUnauthorized, "invalid_credentials"}},
+	{domain.ErrInvalidAPIKey, problemSpec{http.StatusUnauthorized, "invalid_credentials"}},
+	{domain.ErrForbidden, problemSpec{http.StatusForbidden, "forbidden"}},
+	{errMissingScope, problemSpec{http.StatusForbidden, "insufficient_scope"}},
+	{errAPIKeyNotAllowed, problemSpec{http.StatusForbidden, "api_key_not_allowed"}},
+	{domain.ErrTenantMismatch, problemSpec{http.StatusForbidden, "tenant_mismatch"}},
+	{domain.ErrTenantRequired, problemSpec{http.StatusForbidden, "tenant_required"}},
+	{domain.ErrValidation, problemSpec{http.StatusBadRequest, "validation_failed"}},
+	{errInvalidBody, problemSpec{http.StatusBadRequest, "invalid_body"}},
+	{errInvalidQuery, problemSpec{http.StatusBadRequest, "invalid_query"}},
+	{errIdempotencyKeyTooLong, problemSpec{http.StatusBadRequest, "idempotency_key_too_long"}},
+	{domain.ErrInvalidPeriod, problemSpec{http.StatusBadRequest, "invalid_period"}},
+	{domain.ErrInvalidBillingPeriod, problemSpec{http.StatusBadRequest, "invalid_billing_period"}},
+	{domain.ErrInvalidCurrency, problemSpec{http.StatusBadRequest, "invalid_currency"}},
+	{domain.ErrEffectiveFromNoPrice, problemSpec{http.StatusBadRequest, "effective_from_without_price"}},
+	{domain.ErrInvalidProration, problemSpec{http.StatusBadRequest, "invalid_proration"}},
+	{domain.ErrInvalidStatus, problemSpec{http.StatusBadRequest, "invalid_status"}},
+	{domain.ErrInvalidCursor, problemSpec{http.StatusBadRequest, "invalid_cursor"}},
+	{domain.ErrInvalidSort, problemSpec{http.StatusBadRequest, "invalid_sort"}},
+	{domain.ErrInvalidLimit, problemSpec{http.StatusBadRequest, "invalid_limit"}},
+	{domain.ErrInvalidScope, problemSpec{http.StatusBadRequest, "invalid_scope"}},
+	{domain.ErrInvalidRatesFile, problemSpec{http.StatusBadRequest, "invalid_rates_file"}},
+	{domain.ErrSubscriptionNotFound, problemSpec{http.StatusNotFound, "subscription_not_found"}},
+	{domain.ErrAPIKeyNotFound, problemSpec{http.StatusNotFound, "api_key_not_found"}},
+	{domain.ErrIdempotencyInProgress, problemSpec{http.StatusConflict, "idempotency_in_progress"}},
+	{domain.ErrInvalidTransition, problemSpec{http.StatusConflict, "invalid_status_transition"}},
+	{domain.ErrVersionMismatch, problemSpec{http.StatusPreconditionFailed, "version_mismatch"}},
+	{domain.ErrIdempotencyKeyReused, problemSpec{http.StatusUnprocessableEntity, "idempotency_key_reused"}},
+	{domain.ErrExchangeRateNotFound, problemSpec{http.StatusUnprocessableEntity, "exchange_rate_not_found"}},
+	{errTooManyRequests, problemSpec{http.StatusTooManyRequests, "rate_limited"}},
+}
+
+// Внутренняя ошибка сервера
+var internalProblem = problemSpec{http.StatusInternalServerError, "internal"}
+
+// Вид ошибки API для ошибки err
+func problemFor(err error) problemSpec {
+	for _, p := range problemSpecs {
+		if errors.Is(err, p.err) {
+			return p.spec
+		}
+	}
+
+	return internalProblem
+}
+
+// Ошибка с пояснением для клиента, которое попадает в поле detail ответа.
+// Пояснение задается ключом каталога сообщений detail.<key> и аргументами
+type detailedError struct {
+	err  error
+	key  string
+	args []any
+}
+
+func (e *detailedError) Error() string {
+	return e.err.Error() + ": " + i18n.Message(language.Russian, "detail."+e.key, e.args...)
+}
+
+func (e *detailedError) Unwrap() error {
+	return e.err
+}
+
+// Добавление к ошибке пояснения для клиента
+func withDetail(err error, key string, args ...any) error {
+	return &detailedError{err: err, key: key, args: args}
+}
+
+// Ответ об ошибке в формате application/problem+json (RFC 7807).
+//
+// Код и статус ответа определяются по ошибке в одном месте - problemSpecs.
+// Заголовок, пояснение и сообщения об ошибках полей переводятся на язык запроса.
+// Ошибки клиента логируются как Warn, внутренние ошибки - как Error, их текст клиенту не отдается
+func (h *Handler) fail(c *gin.Context, err error, attrs ...slog.Attr) {
+	spec := problemFor(err)
+	lang := i18n.LanguageFromContext(c.Request.Context())
+
+	problem := domain.Problem{
+		Type:     problemTypePrefix + spec.code,
+		Code:     spec.code,
+		Title:    i18n.Message(lang, "problem."+spec.code),
+		Status:   spec.status,
+		Instance: requestID(c),
+	}
+
+	var detailed *detailedError
+	if spec.status < http.StatusInternalServerError && errors.As(err, &detailed) {
+		problem.Detail = i18n.Message(lang, "detail."+detailed.key, detailed.args...)
+	}
+
+	var validation *domain.ValidationError
+	if errors.As(err, &validation) {
+		problem.Errors = localizeFields(lang, validation.Fields)
+	}
+
+	args := make([]any, 0, len(attrs)+4)
+	for _, attr := range attrs {
+		args = append(args, attr)
+	}
+	args = append(args,
+		slog.String("code", spec.code),
+		slog.String("path", c.FullPath()),
+		slog.String("request_id", problem.Instance),
+		slog.String("error", err.Error()),
+	)
+
+	if spec.status >= http.StatusInternalServerError {
+		h.log.Error("ошибка при обработке запроса", args...)
+	} else {
+		h.log.Warn("запрос отклонен", args...)
+	}
+
+	c.Header("Content-Type", "application/problem+json; charset=utf-8")
+	c.AbortWithStatusJSON(spec.status, problem)
+}
 
-	c.AbortWithStatusJSON(statusCode, domain.ErrorResponse{
-		Error: message,
-	})
+// Ответ 500 после паники в обработчике
+func (h *Handler) recoverPanic(c *gin.Context, recovered any) {
+	h.fail(c, fmt.Errorf("%w: паника: %v", domain.ErrInternal, recovered))
 }
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/levinOo/go-crudl-task/internal/domain"
@@ -13,74 +17,245 @@ import (
 
 // Структура создания подписки
 type createSubInput struct {
-	ServiceName string `json:"service_name" binding:"required"`
-	Price       int64  `json:"price" binding:"required"`
-	UserID      string `json:"user_id" binding:"required"`
-	StartDate   string `json:"start_date" binding:"required"`
+	ServiceName   string  `json:"service_name" binding:"required,max=255"`
+	Price         int64   `json:"price" binding:"required,gt=0" minimum:"1"`
+	Currency      string  `json:"currency" binding:"omitempty,iso4217" example:"RUB" default:"RUB"`
+	BillingPeriod string  `json:"billing_period" binding:"omitempty,oneof=weekly monthly quarterly yearly" enums:"weekly,monthly,quarterly,yearly" default:"monthly"`
+	UserID        string  `json:"user_id" binding:"required,uuid" format:"uuid"`
+	Status        string  `json:"status" binding:"omitempty,oneof=active trial" enums:"active,trial"` // Начальный статус, по умолчанию trial при trial_end_date, иначе active
+	StartDate     string  `json:"start_date" binding:"required" example:"2025-07-15"`                 // Дата YYYY-MM-DD, месяц MM-YYYY (его первый день) или момент времени RFC 3339 (дата в часовом поясе tz)
+	EndDate       *string `json:"end_date" example:"2025-12-31"`                                      // Дата YYYY-MM-DD, месяц MM-YYYY (его последний день) или момент времени RFC 3339 (дата в часовом поясе tz), включительно
+	TrialEndDate  *string `json:"trial_end_date" example:"2025-07-29"`                                // Последний день пробного периода, включительно: дата YYYY-MM-DD, месяц MM-YYYY (его последний день) или момент времени RFC 3339 (дата в часовом поясе tz)
 }
 
-// Структура обновления подписки
+// Проверка данных создания подписки, loc - часовой пояс запроса.
+// Возвращает подписку или ошибку валидации со всеми нарушенными полями
+func (in createSubInput) subscription(loc *time.Location) (domain.Subscription, error) {
+	errs := fieldErrors{loc: loc}
+
+	errs.check(in)
+	startDate := errs.date("start_date", in.StartDate)
+	endDate := errs.optionalEndDate("end_date", in.EndDate)
+	errs.notBefore("end_date", endDate, "start_date", startDate)
+	trialEndDate := errs.optionalEndDate("trial_end_date", in.TrialEndDate)
+	errs.notBefore("trial_end_date", trialEndDate, "start_date", startDate)
+
+	if err := errs.err(); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return domain.Subscription{
+		ServiceName:   in.ServiceName,
+		Price:         int(in.Price),
+		Currency:      in.Currency,
+		BillingPeriod: domain.BillingPeriod(in.BillingPeriod),
+		UserID:        strings.ToLower(in.UserID),
+		Status:        domain.SubscriptionStatus(in.Status),
+		StartDate:     startDate,
+		EndDate:       endDate,
+		TrialEndDate:  trialEndDate,
+	}, nil
+}
+
+// Структура полной замены подписки (PUT). Владелец подписки не меняется
+type replaceSubInput struct {
+	ServiceName   string  `json:"service_name" binding:"required,max=255"`
+	Price         int64   `json:"price" binding:"required,gt=0" minimum:"1"`
+	Currency      string  `json:"currency" binding:"omitempty,iso4217" example:"RUB" default:"RUB"`
+	BillingPeriod string  `json:"billing_period" binding:"omitempty,oneof=weekly monthly quarterly yearly" enums:"weekly,monthly,quarterly,yearly" default:"monthly"`
+	StartDate     string  `json:"start_date" binding:"required" example:"2025-07-15"` // Дата YYYY-MM-DD, месяц MM-YYYY (его первый день) или момент времени RFC 3339 (дата в часовом поясе tz)
+	EndDate       *string `json:"end_date" example:"2025-12-31"`                      // Дата YYYY-MM-DD, месяц MM-YYYY (его последний день) или момент времени RFC 3339 (дата в часовом поясе tz), включительно
+}
+
+// Проверка данных замены подписки, loc - часовой пояс запроса.
+// Возвращает новые данные подписки или ошибку валидации со всеми нарушенными полями
+func (in replaceSubInput) subscription(loc *time.Location) (domain.Subscription, error) {
+	errs := fieldErrors{loc: loc}
+
+	errs.check(in)
+	startDate := errs.date("start_date", in.StartDate)
+	endDate := errs.optionalEndDate("end_date", in.EndDate)
+	errs.notBefore("end_date", endDate, "start_date", startDate)
+
+	if err := errs.err(); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return domain.Subscription{
+		ServiceName:   in.ServiceName,
+		Price:         int(in.Price),
+		Currency:      in.Currency,
+		BillingPeriod: domain.BillingPeriod(in.BillingPeriod),
+		StartDate:     startDate,
+		EndDate:       endDate,
+	}, nil
+}
+
+// Структура частичного обновления подписки (JSON Merge Patch, RFC 7396).
+// Отсутствующие поля не меняются, end_date: null делает подписку бессрочной
 type updateSubInput struct {
-	Price   *int64  `json:"price"`
-	EndDate *string `json:"end_date"`
+	ServiceName   *string        `json:"service_name" binding:"omitempty,min=1,max=255"`
+	Price         *int64         `json:"price" binding:"omitempty,gt=0" minimum:"1"`
+	Currency      *string        `json:"currency" binding:"omitempty,iso4217" example:"RUB"`
+	BillingPeriod *string        `json:"billing_period" binding:"omitempty,oneof=weekly monthly quarterly yearly" enums:"weekly,monthly,quarterly,yearly"`
+	StartDate     *string        `json:"start_date" example:"2025-07-15"`                    // Дата YYYY-MM-DD, месяц MM-YYYY (его первый день) или момент времени RFC 3339 (дата в часовом поясе tz)
+	EndDate       nullableString `json:"end_date" swaggertype:"string" example:"2025-12-31"` // Дата YYYY-MM-DD, месяц MM-YYYY (его последний день) или момент времени RFC 3339 (дата в часовом поясе tz), включительно
+	EffectiveFrom *string        `json:"effective_from" example:"2025-09-01"`                // Дата YYYY-MM-DD, месяц MM-YYYY (его первый день) или момент времени RFC 3339 (дата в часовом поясе tz), с которой действует новая цена, по умолчанию текущая дата
 }
 
-// Парсинг даты
-func parseDate(dateStr string) (time.Time, error) {
-	t, err := time.Parse("01-2006", dateStr)
-	if err != nil {
-		return time.Time{}, err
+// Проверка данных частичного обновления, loc - часовой пояс запроса.
+// Возвращает изменения подписки или ошибку валидации со всеми нарушенными полями.
+// Порядок дат проверяет сервис: ему известны текущие даты подписки
+func (in updateSubInput) update(loc *time.Location) (domain.UpdateSubscriptionInput, error) {
+	errs := fieldErrors{loc: loc}
+
+	errs.check(in)
+
+	update := domain.UpdateSubscriptionInput{
+		ServiceName:   in.ServiceName,
+		Price:         in.Price,
+		Currency:      in.Currency,
+		StartDate:     errs.optionalDate("start_date", in.StartDate),
+		EffectiveFrom: errs.optionalDate("effective_from", in.EffectiveFrom),
+	}
+
+	if in.BillingPeriod != nil {
+		billingPeriod := domain.BillingPeriod(*in.BillingPeriod)
+		update.BillingPeriod = &billingPeriod
+	}
+
+	// null сбрасывает дату окончания
+	if in.EndDate.Set {
+		if in.EndDate.Value == nil {
+			update.ClearEndDate = true
+		} else {
+			update.EndDate = errs.optionalEndDate("end_date", in.EndDate.Value)
+		}
+	}
+
+	if err := errs.err(); err != nil {
+		return domain.UpdateSubscriptionInput{}, err
 	}
-	return t, nil
+
+	return update, nil
+}
+
+// Строковое поле JSON, в котором отсутствие значения отличается от null
+type nullableString struct {
+	Set   bool    // Поле присутствует в теле запроса
+	Value *string // nil, если передан null
+}
+
+// Разбор значения поля. Вызывается только если поле есть в JSON, в том числе для null
+func (n *nullableString) UnmarshalJSON(data []byte) error {
+	n.Set = true
+
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	n.Value = &value
+
+	return nil
 }
 
 // CreateSubscription - создание подписки
 //
 //	@Summary		Создание подписки
-//	@Description	Создать новую подписку
+//	@Description	Создать новую подписку. Без end_date подписка бессрочная.
+//	@Description	С заголовком Idempotency-Key повтор запроса того же пользователя с теми же данными возвращает исходный ответ и не создает дубликат
 //	@Tags			subscriptions
 //	@Accept			json
 //	@Produce		json
-//	@Param			body	body		createSubInput		true	"Данные подписки"
-//	@Success		201		{object}	map[string]string	"ID подписки"
-//	@Failure		400		{object}	domain.ErrorResponse		"Неверное тело запроса"
-//	@Failure		500		{object}	domain.ErrorResponse		"Внутренняя ошибка сервера"
+//	@Param			Idempotency-Key	header		string				false	"Ключ идемпотентности запроса"
+//	@Param			body			body		createSubInput		true	"Данные подписки"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		201				{object}	subscriptionView	"Созданная подписка"
+//	@Header			201				{string}	Location			"Адрес созданной подписки"
+//	@Failure		400				{object}	domain.Problem	"Неверное тело запроса"
+//	@Failure		401				{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403				{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		409				{object}	domain.Problem	"Запрос с этим ключом еще выполняется"
+//	@Failure		422				{object}	domain.Problem	"Ключ уже использован с другим телом запроса"
+//	@Failure		429				{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500				{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/subscriptions [post]
 func (h *Handler) createSubscription(c *gin.Context) {
 	var input createSubInput
 
 	// Читаем JSON
-	if err := c.ShouldBindJSON(&input); err != nil {
-		h.log.Warn("ошибка при чтении JSON", slog.String("error", err.Error()))
-		newErrorResponse(c, http.StatusBadRequest, "Неверное тело запроса")
+	if err := readJSON(c, &input); err != nil {
+		h.fail(c, err)
 		return
 	}
 
-	// Парсим дату начала
-	startDate, err := parseDate(input.StartDate)
+	// Проверяем поля
+	sub, err := input.subscription(domain.LocationFromContext(c.Request.Context()))
 	if err != nil {
-		h.log.Warn("ошибка парсинга даты", slog.String("date", input.StartDate), slog.String("error", err.Error()))
-		newErrorResponse(c, http.StatusBadRequest, "Неверный формат даты начала. Ожидается MM-YYYY")
+		h.fail(c, err)
 		return
 	}
 
-	sub := domain.Subscription{
-		ServiceName: input.ServiceName,
-		Price:       int(input.Price),
-		UserID:      input.UserID,
-		StartDate:   startDate,
-		EndDate:     nil,
+	// Проверяем ключ идемпотентности: повтор получает сохраненный ответ,
+	// но только если пользователь все еще может создать такую подписку
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if idempotencyKey != "" {
+		if err := h.services.AuthorizeCreate(c.Request.Context(), sub); err != nil {
+			h.fail(c, err)
+			return
+		}
+		if !h.beginIdempotent(c, idempotencyKey, input) {
+			return
+		}
 	}
 
-	// Вызываем слой сервис
-	id, err := h.services.Create(c.Request.Context(), sub)
+	var (
+		created  domain.Subscription
+		response []byte
+	)
+
+	// Вызываем слой сервис и сериализуем ответ
+	create := func(ctx context.Context) (int, []byte, error) {
+		created, err = h.services.Create(ctx, sub)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		response, err = json.Marshal(dateFormatOf(c).subscription(created))
+		if err != nil {
+			return 0, nil, fmt.Errorf("Ошибка при сериализации подписки: %w", err)
+		}
+
+		return http.StatusCreated, response, nil
+	}
+
+	// С ключом идемпотентности ответ сохраняется для повторов в одной транзакции с подпиской
+	if idempotencyKey != "" {
+		err = h.idempotency.Complete(c.Request.Context(), h.principal(c).UserID, idempotencyKey, create)
+	} else {
+		_, _, err = create(c.Request.Context())
+	}
 	if err != nil {
-		h.log.Error("ошибка при создании подписки", slog.String("error", err.Error()))
-		newErrorResponse(c, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		h.fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"id": id})
+	h.writeCreated(c, created.ID, created.Version, response)
+}
+
+// Ответ о созданной подписке с уже сериализованным телом
+func (h *Handler) writeCreated(c *gin.Context, id string, version int64, response []byte) {
+	c.Header("Location", c.Request.URL.Path+"/"+id)
+	setETag(c, version)
+	c.Data(http.StatusCreated, "application/json; charset=utf-8", response)
 }
 
 // GetSubscription - получение подписки по ID
@@ -90,138 +265,201 @@ func (h *Handler) createSubscription(c *gin.Context) {
 //	@Tags			subscriptions
 //	@Produce		json
 //	@Param			id	path		string	true	"ID подписки"
-//	@Success		200	{object}	domain.Subscription
-//	@Failure		404	{object}	domain.ErrorResponse	"Подписка не найдена"
-//	@Failure		500	{object}	domain.ErrorResponse	"Внутренняя ошибка сервера"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200	{object}	subscriptionView
+//	@Header			200	{string}	ETag					"Версия подписки для If-Match"
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404	{object}	domain.Problem	"Подписка не найдена"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/subscriptions/{id} [get]
 func (h *Handler) getSubscription(c *gin.Context) {
 	// Достаем id из URL
 	id := c.Param("id")
 	if id == "" {
-		h.log.Error("ID подписки не может быть пустым", slog.String("id", id))
-		newErrorResponse(c, http.StatusBadRequest, "ID подписки не может быть пустым")
+		h.fail(c, fieldError("id", "required", ""))
 		return
 	}
 
 	// Вызываем слой сервис
 	sub, err := h.services.Get(c.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, domain.ErrSubscriptionNotFound) {
-			h.log.Error("подписка не найдена", slog.String("id", id), slog.String("error", err.Error()))
-			newErrorResponse(c, http.StatusNotFound, "Подписка не найдена")
-			return
-		}
+		h.fail(c, err, slog.String("id", id))
+		return
+	}
+
+	setETag(c, sub.Version)
+	c.JSON(http.StatusOK, dateFormatOf(c).subscription(sub))
+}
+
+// ReplaceSubscription - полная замена
+//
+//	@Summary		Замена подписки
+//	@Description	Полностью заменить данные подписки, кроме владельца. Незаданные необязательные поля получают значения по умолчанию,
+//	@Description	без end_date подписка становится бессрочной
+//	@Tags			subscriptions
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"ID подписки"
+//	@Param			If-Match	header		string				false	"ETag подписки из предыдущего ответа"
+//	@Param			body	body		replaceSubInput		true	"Новые данные подписки"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200		{object}	subscriptionView	"Обновленная подписка"
+//	@Failure		400		{object}	domain.Problem	"Неверные данные"
+//	@Failure		401		{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403		{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404		{object}	domain.Problem	"Подписка не найдена"
+//	@Failure		412		{object}	domain.Problem	"Версия подписки не совпадает с If-Match"
+//	@Failure		429		{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500		{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/subscriptions/{id} [put]
+func (h *Handler) replaceSubscription(c *gin.Context) {
+	// Достаем id из URL
+	id := c.Param("id")
+	if id == "" {
+		h.fail(c, fieldError("id", "required", ""))
+		return
+	}
+
+	var input replaceSubInput
+
+	// Читаем JSON
+	if err := readJSON(c, &input); err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	// Проверяем поля
+	sub, err := input.subscription(domain.LocationFromContext(c.Request.Context()))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	ifVersion, ok := h.ifMatchVersion(c)
+	if !ok {
+		return
+	}
 
-		h.log.Error("ошибка при получении подписки", slog.String("id", id), slog.String("error", err.Error()))
-		newErrorResponse(c, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+	// Вызываем слой сервис
+	replaced, err := h.services.Replace(c.Request.Context(), id, sub, ifVersion)
+	if err != nil {
+		h.fail(c, err, slog.String("id", id))
 		return
 	}
 
-	c.JSON(http.StatusOK, sub)
+	setETag(c, replaced.Version)
+	c.JSON(http.StatusOK, dateFormatOf(c).subscription(replaced))
 }
 
-// UpdateSubscription - обновление (цена, дата окончания)
+// UpdateSubscription - частичное обновление
 //
 //	@Summary		Обновление данных подписки
-//	@Description	Обновить цену или дату окончания подписки
+//	@Description	Частично обновить подписку по правилам JSON Merge Patch (RFC 7396): отсутствующие поля не меняются,
+//	@Description	"end_date": null делает подписку бессрочной.
+//	@Description	Новая цена действует с даты effective_from (по умолчанию с текущей даты), уже сделанные списания не меняются
 //	@Tags			subscriptions
 //	@Accept			json
+//	@Accept			application/merge-patch+json
 //	@Produce		json
 //	@Param			id		path		string				true	"ID подписки"
+//	@Param			If-Match	header		string				false	"ETag подписки из предыдущего ответа"
 //	@Param			body	body		updateSubInput		true	"Данные для обновления"
-//	@Success		200		{object}	map[string]string	"Статус и сообщение"
-//	@Failure		400		{object}	domain.ErrorResponse	"Неверные данные"
-//	@Failure		404		{object}	domain.ErrorResponse	"Подписка не найдена"
-//	@Failure		500		{object}	domain.ErrorResponse	"Внутренняя ошибка сервера"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200		{object}	subscriptionView	"Обновленная подписка"
+//	@Failure		400		{object}	domain.Problem	"Неверные данные"
+//	@Failure		401		{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403		{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404		{object}	domain.Problem	"Подписка не найдена"
+//	@Failure		412		{object}	domain.Problem	"Версия подписки не совпадает с If-Match"
+//	@Failure		429		{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500		{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/subscriptions/{id} [patch]
 func (h *Handler) updateSubscription(c *gin.Context) {
 	// Достаем id из URL
 	id := c.Param("id")
 	if id == "" {
-		h.log.Error("ID подписки не может быть пустым", slog.String("id", id))
-		newErrorResponse(c, http.StatusBadRequest, "ID подписки не может быть пустым")
+		h.fail(c, fieldError("id", "required", ""))
 		return
 	}
 
 	var input updateSubInput
 
 	// Читаем JSON
-	if err := c.ShouldBindJSON(&input); err != nil {
-		h.log.Warn("ошибка при чтении JSON", slog.String("error", err.Error()))
-		newErrorResponse(c, http.StatusBadRequest, "Неверное тело запроса")
+	if err := readJSON(c, &input); err != nil {
+		h.fail(c, err)
 		return
 	}
 
-	var endDate *time.Time
-	// Если прислали дату окончания — парсим её
-	if input.EndDate != nil {
-		t, err := parseDate(*input.EndDate)
-		if err != nil {
-			h.log.Error("ошибка парсинга даты", slog.String("date", *input.EndDate), slog.String("error", err.Error()))
-			newErrorResponse(c, http.StatusBadRequest, "Неверный формат даты окончания. Ожидается MM-YYYY")
-			return
-		}
-		endDate = &t
+	// Проверяем поля
+	updateData, err := input.update(domain.LocationFromContext(c.Request.Context()))
+	if err != nil {
+		h.fail(c, err)
+		return
 	}
 
-	updateData := domain.UpdateSubscriptionInput{
-		Price:   input.Price,
-		EndDate: endDate,
+	ifVersion, ok := h.ifMatchVersion(c)
+	if !ok {
+		return
 	}
+	updateData.IfVersion = ifVersion
 
 	// Вызываем слой сервис
-	err := h.services.Update(c.Request.Context(), id, updateData)
+	sub, err := h.services.Update(c.Request.Context(), id, updateData)
 	if err != nil {
-		if errors.Is(err, domain.ErrSubscriptionNotFound) {
-			h.log.Error("подписка не найдена", slog.String("id", id), slog.String("error", err.Error()))
-			newErrorResponse(c, http.StatusNotFound, "Подписка не найдена")
-			return
-		}
-		if errors.Is(err, domain.ErrInvalidPeriod) {
-			h.log.Error("ошибка при обновлении подписки", slog.String("id", id), slog.String("error", err.Error()))
-			newErrorResponse(c, http.StatusBadRequest, "Дата окончания не может быть раньше даты начала")
-			return
-		}
-
-		h.log.Error("ошибка при обновлении подписки", slog.String("id", id), slog.String("error", err.Error()))
-		newErrorResponse(c, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		h.fail(c, err, slog.String("id", id))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "ok", "message": "Подписка обновлена"})
+	setETag(c, sub.Version)
+	c.JSON(http.StatusOK, dateFormatOf(c).subscription(sub))
 }
 
 // DeleteSubscription - удаление
 //
 //	@Summary		Удаление подписки
-//	@Description	Удалить подписку по ID
+//	@Description	Переместить подписку в корзину. Ее можно восстановить, пока не истек срок хранения корзины
 //	@Tags			subscriptions
 //	@Produce		json
 //	@Param			id	path	string	true	"ID подписки"
-//	@Success		204	"Подписка успешно удалена"
-//	@Failure		404	{object}	domain.ErrorResponse	"Подписка не найдена"
-//	@Failure		500	{object}	domain.ErrorResponse	"Внутренняя ошибка сервера"
+//	@Param			If-Match	header		string				false	"ETag подписки из предыдущего ответа"
+//	@Success		204	"Подписка перемещена в корзину"
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404	{object}	domain.Problem	"Подписка не найдена"
+//	@Failure		412		{object}	domain.Problem	"Версия подписки не совпадает с If-Match"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/subscriptions/{id} [delete]
 func (h *Handler) deleteSubscription(c *gin.Context) {
 	// Достаем id из URL
 	id := c.Param("id")
 	if id == "" {
-		h.log.Error("ID подписки не может быть пустым", slog.String("id", id))
-		newErrorResponse(c, http.StatusBadRequest, "ID подписки не может быть пустым")
+		h.fail(c, fieldError("id", "required", ""))
+		return
+	}
+
+	ifVersion, ok := h.ifMatchVersion(c)
+	if !ok {
 		return
 	}
 
 	// Вызываем слой сервис
-	err := h.services.Delete(c.Request.Context(), id)
+	err := h.services.Delete(c.Request.Context(), id, ifVersion)
 	if err != nil {
-		if errors.Is(err, domain.ErrSubscriptionNotFound) {
-			h.log.Error("подписка не найдена", slog.String("id", id), slog.String("error", err.Error()))
-			newErrorResponse(c, http.StatusNotFound, "Подписка не найдена")
-			return
-		}
-
-		h.log.Error("ошибка при удалении подписки", slog.String("id", id), slog.String("error", err.Error()))
-		newErrorResponse(c, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		h.fail(c, err, slog.String("id", id))
 		return
 	}
 
@@ -229,103 +467,422 @@ func (h *Handler) deleteSubscription(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// Параметры списка подписок
+type listSubsQuery struct {
+	ServiceName string `form:"service_name"`
+	ActiveAt    string `form:"active_at"` // Дата YYYY-MM-DD, месяц MM-YYYY или момент времени RFC 3339 (дата в часовом поясе tz)
+	PriceMin    *int64 `form:"price_min" binding:"omitempty,gte=0"`
+	PriceMax    *int64 `form:"price_max" binding:"omitempty,gte=0"`
+	HasEndDate  *bool  `form:"has_end_date"`
+	Status      string `form:"status" binding:"omitempty,oneof=trial active paused cancelled expired"`
+	Sort        string `form:"sort" binding:"omitempty,oneof=start_date price service_name"`
+	Order       string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit       int    `form:"limit" binding:"omitempty,min=1,max=500"`
+	Cursor      string `form:"cursor"`
+}
+
 // GetList - получение списка (с фильтрацией по user_id)
 //
 //	@Summary		Получение списка подписок
-//	@Description	Получить список всех подписок пользователя
+//	@Description	Получить страницу списка подписок пользователя с фильтрацией и сортировкой.
+//	@Description	Для получения следующей страницы передайте next_cursor из ответа в параметр cursor, не меняя остальные параметры
 //	@Tags			subscriptions
 //	@Produce		json
-//	@Param			user_id	query		string	true	"UUID пользователя"
-//	@Success		200		{array}		domain.Subscription
-//	@Failure		500		{object}	domain.ErrorResponse	"Внутренняя ошибка сервера"
+//	@Param			user_id			query		string	false	"UUID пользователя, по умолчанию - из токена"
+//	@Param			service_name	query		string	false	"Название сервиса"
+//	@Param			active_at		query		string	false	"Подписка активна в день YYYY-MM-DD, в день момента времени RFC 3339 в часовом поясе tz или хотя бы один день месяца MM-YYYY"
+//	@Param			price_min		query		int		false	"Минимальная цена"
+//	@Param			price_max		query		int		false	"Максимальная цена"
+//	@Param			has_end_date	query		bool	false	"Наличие даты окончания"
+//	@Param			status			query		string	false	"Статус подписки"	Enums(trial, active, paused, cancelled, expired)
+//	@Param			sort			query		string	false	"Поле сортировки"		Enums(start_date, price, service_name)	default(start_date)
+//	@Param			order			query		string	false	"Направление сортировки"	Enums(asc, desc)						default(asc)
+//	@Param			limit			query		int		false	"Размер страницы"		minimum(1)								maximum(500)	default(50)
+//	@Param			cursor			query		string	false	"Курсор следующей страницы"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200				{object}	subscriptionPageView
+//	@Failure		400				{object}	domain.Problem	"Неверные параметры"
+//	@Failure		401				{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403				{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		429				{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500				{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
 //	@Router			/subscriptions [get]
 func (h *Handler) getList(c *gin.Context) {
+	filter, err := h.parseListFilter(c)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	h.writeList(c, filter)
+}
+
+// GetTrash - получение списка подписок в корзине
+//
+//	@Summary		Получение корзины подписок
+//	@Description	Получить страницу списка удаленных подписок пользователя. Параметры те же, что у списка подписок.
+//	@Description	Удаленные подписки не участвуют в отчетах и окончательно удаляются после срока хранения корзины
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			user_id			query		string	false	"UUID пользователя, по умолчанию - из токена"
+//	@Param			service_name	query		string	false	"Название сервиса"
+//	@Param			active_at		query		string	false	"Подписка активна в день YYYY-MM-DD, в день момента времени RFC 3339 в часовом поясе tz или хотя бы один день месяца MM-YYYY"
+//	@Param			price_min		query		int		false	"Минимальная цена"
+//	@Param			price_max		query		int		false	"Максимальная цена"
+//	@Param			has_end_date	query		bool	false	"Наличие даты окончания"
+//	@Param			status			query		string	false	"Статус подписки"	Enums(trial, active, paused, cancelled, expired)
+//	@Param			sort			query		string	false	"Поле сортировки"		Enums(start_date, price, service_name)	default(start_date)
+//	@Param			order			query		string	false	"Направление сортировки"	Enums(asc, desc)						default(asc)
+//	@Param			limit			query		int		false	"Размер страницы"		minimum(1)								maximum(500)	default(50)
+//	@Param			cursor			query		string	false	"Курсор следующей страницы"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200				{object}	subscriptionPageView
+//	@Failure		400				{object}	domain.Problem	"Неверные параметры"
+//	@Failure		401				{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403				{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		429				{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500				{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/subscriptions/trash [get]
+func (h *Handler) getTrash(c *gin.Context) {
+	filter, err := h.parseListFilter(c)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	filter.Deleted = true
+
+	h.writeList(c, filter)
+}
+
+// Чтение параметров списка подписок
+func (h *Handler) parseListFilter(c *gin.Context) (domain.ListSubscriptionsFilter, error) {
+	// Пользователь из query params, по умолчанию - сам аутентифицированный пользователь
+	userID := h.requestedUser(c)
+
+	var query listSubsQuery
+
+	// Читаем параметры фильтрации и пагинации
+	if err := c.ShouldBindQuery(&query); err != nil {
+		return domain.ListSubscriptionsFilter{}, bindingError(err, errInvalidQuery)
+	}
+
+	filter := domain.ListSubscriptionsFilter{
+		UserID:      userID,
+		ServiceName: query.ServiceName,
+		PriceMin:    query.PriceMin,
+		PriceMax:    query.PriceMax,
+		HasEndDate:  query.HasEndDate,
+		Status:      domain.SubscriptionStatus(query.Status),
+		SortBy:      query.Sort,
+		SortDesc:    query.Order == "desc",
+		Limit:       query.Limit,
+		Cursor:      query.Cursor,
+	}
+
+	// Парсим день или месяц активности
+	if query.ActiveAt != "" {
+		activeFrom, month, err := parseDate(query.ActiveAt, domain.LocationFromContext(c.Request.Context()))
+		if err != nil {
+			return domain.ListSubscriptionsFilter{}, dateFieldError("active_at")
+		}
+
+		activeTo := activeFrom
+		if month {
+			activeTo = lastDayOfMonth(activeFrom)
+		}
+		filter.ActiveFrom, filter.ActiveTo = &activeFrom, &activeTo
+	}
+
+	return filter, nil
+}
+
+// Получение и отправка страницы списка подписок
+func (h *Handler) writeList(c *gin.Context, filter domain.ListSubscriptionsFilter) {
+	// Вызываем слой сервис
+	page, err := h.services.List(c.Request.Context(), filter)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dateFormatOf(c).subscriptionPage(page))
+}
+
+// RestoreSubscription - восстановление из корзины
+//
+//	@Summary		Восстановление подписки
+//	@Description	Восстановить удаленную подписку из корзины
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			id	path		string	true	"ID подписки"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200	{object}	subscriptionView		"Восстановленная подписка"
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404	{object}	domain.Problem	"Подписка не найдена в корзине"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/subscriptions/{id}/restore [post]
+func (h *Handler) restoreSubscription(c *gin.Context) {
 	// Достаем id из URL
-	userID := c.Query("user_id")
-	if userID == "" {
-		h.log.Error("ID пользователя не может быть пустым", slog.String("user_id", userID))
-		newErrorResponse(c, http.StatusBadRequest, "ID пользователя не может быть пустым")
+	id := c.Param("id")
+	if id == "" {
+		h.fail(c, fieldError("id", "required", ""))
 		return
 	}
 
 	// Вызываем слой сервис
-	subs, err := h.services.List(c.Request.Context(), userID)
+	sub, err := h.services.Restore(c.Request.Context(), id)
 	if err != nil {
-		h.log.Error("ошибка при получении списка", slog.String("error", err.Error()))
-		newErrorResponse(c, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		if errors.Is(err, domain.ErrSubscriptionNotFound) {
+			err = withDetail(err, "not_in_trash")
+		}
+		h.fail(c, err, slog.String("id", id))
 		return
 	}
 
-	// Если список пустой, возвращаем пустой массив []
-	if subs == nil {
-		subs = []domain.Subscription{}
+	setETag(c, sub.Version)
+	c.JSON(http.StatusOK, dateFormatOf(c).subscription(sub))
+}
+
+// GetPrices - история цен подписки
+//
+//	@Summary		История цен подписки
+//	@Description	Получить цены подписки с датами, с которых они действуют, в хронологическом порядке
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			id	path		string	true	"ID подписки"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200	{array}		subscriptionPriceView
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404	{object}	domain.Problem	"Подписка не найдена"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/subscriptions/{id}/prices [get]
+func (h *Handler) getPrices(c *gin.Context) {
+	// Достаем id из URL
+	id := c.Param("id")
+	if id == "" {
+		h.fail(c, fieldError("id", "required", ""))
+		return
+	}
+
+	// Вызываем слой сервис
+	prices, err := h.services.Prices(c.Request.Context(), id)
+	if err != nil {
+		h.fail(c, err, slog.String("id", id))
+		return
 	}
 
-	c.JSON(http.StatusOK, subs)
+	c.JSON(http.StatusOK, dateFormatOf(c).subscriptionPrices(prices))
 }
 
-// GetTotalCost - подсчет суммарной стоимости подписок за выбранный период с фильтрацией
+// GetHistory - история изменений подписки
 //
-//	@Summary		Подсчитать суммарную стоимость подписок
-//	@Description	Получить суммарную стоимость подписок за выбранный период с фильтрацией по user_id и названию подписки
+//	@Summary		История изменений подписки
+//	@Description	Получить все изменения подписки в хронологическом порядке: кто, когда и как ее менял.
+//	@Description	История доступна и для удаленных подписок
 //	@Tags			subscriptions
 //	@Produce		json
-//	@Param			user_id			query		string			true	"UUID пользователя"
-//	@Param			service_name	query		string			false	"Название подписки"
-//	@Param			start_date		query		string			true	"Начальная дата (формат MM-YYYY)"
-//	@Param			end_date		query		string			true	"Конечная дата (формат MM-YYYY)"
-//	@Success		200				{object}	map[string]int	"Суммарная стоимость"
-//	@Failure		400				{object}	domain.ErrorResponse	"Неверные параметры"
-//	@Failure		500				{object}	domain.ErrorResponse	"Внутренняя ошибка сервера"
-//	@Router			/subscriptions/total-cost [get]
-func (h *Handler) getTotalCost(c *gin.Context) {
-	// Достаем значеня из query params
-	userID := c.Query("user_id")
-	serviceName := c.Query("service_name")
-	startDateStr := c.Query("start_date")
-	endDateStr := c.Query("end_date")
+//	@Param			id	path		string	true	"ID подписки"
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200	{array}		subscriptionEventView
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		404	{object}	domain.Problem	"История подписки не найдена"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/subscriptions/{id}/history [get]
+func (h *Handler) getHistory(c *gin.Context) {
+	// Достаем id из URL
+	id := c.Param("id")
+	if id == "" {
+		h.fail(c, fieldError("id", "required", ""))
+		return
+	}
 
-	// Проверяем обязательные параметры
-	if userID == "" {
-		h.log.Warn("user_id не указан")
-		newErrorResponse(c, http.StatusBadRequest, "user_id обязателен")
+	// Вызываем слой сервис
+	events, err := h.services.History(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrSubscriptionNotFound) {
+			err = withDetail(err, "history_not_found")
+		}
+		h.fail(c, err, slog.String("id", id))
 		return
 	}
 
-	if startDateStr == "" || endDateStr == "" {
-		h.log.Warn("даты не указаны")
-		newErrorResponse(c, http.StatusBadRequest, "start_date и end_date обязательны")
+	c.JSON(http.StatusOK, dateFormatOf(c).subscriptionEvents(events))
+}
+
+// PurgeSubscriptions - очистка корзины
+//
+//	@Summary		Очистка корзины подписок
+//	@Description	Окончательно удалить подписки, пролежавшие в корзине дольше срока хранения (параметр trash.retention)
+//	@Tags			admin
+//	@Produce		json
+//	@Success		200	{object}	map[string]int64		"Количество удаленных подписок"
+//	@Failure		401	{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403	{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		429	{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500	{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Router			/admin/subscriptions/purge [post]
+func (h *Handler) purgeSubscriptions(c *gin.Context) {
+	// Вызываем слой сервис
+	purged, err := h.services.Purge(c.Request.Context())
+	if err != nil {
+		h.fail(c, err)
 		return
 	}
 
-	// Парсим даты
-	startDate, err := parseDate(startDateStr)
+	h.log.Info("корзина подписок очищена", slog.Int64("purged", purged))
+
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
+
+// GetTotalCost - подсчет суммарной стоимости подписок за выбранный период с фильтрацией
+//
+//	@Summary		Подсчитать суммарную стоимость подписок
+//	@Description	Получить суммарную стоимость подписок за выбранный период с фильтрацией по user_id и названию подписки.
+//	@Description	Учитываются все списания, приходящиеся на период: подписка оплачивается в начале каждого расчетного периода, начиная с даты начала.
+//	@Description	Каждое списание считается по цене, действовавшей на дату списания.
+//	@Description	С proration=daily цена расчетного периода распределяется по его дням, и неполные первый и последний месяцы учитываются пропорционально дням активности
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			user_id			query		string			false	"UUID пользователя, по умолчанию - из токена"
+//	@Param			service_name	query		string			false	"Название подписки"
+//	@Param			start_date		query		string			true	"Начало периода: дата YYYY-MM-DD, месяц MM-YYYY или момент времени RFC 3339 (дата в часовом поясе tz)"
+//	@Param			end_date		query		string			true	"Конец периода включительно: дата YYYY-MM-DD, месяц MM-YYYY или момент времени RFC 3339 (дата в часовом поясе tz)"
+//	@Param			currency		query		string			false	"Валюта отчета (ISO 4217), суммы пересчитываются по загруженным курсам"	default(RUB)
+//	@Param			proration		query		string			false	"Пропорциональный расчет: none - списания целиком, daily - по дням активности"	Enums(none, daily)	default(none)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200				{object}	map[string]any	"Суммарная стоимость и валюта"
+//	@Failure		400				{object}	domain.Problem	"Неверные параметры"
+//	@Failure		401				{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403				{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		422				{object}	domain.Problem	"Не найден курс валюты"
+//	@Failure		429				{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500				{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/subscriptions/total-cost [get]
+func (h *Handler) getTotalCost(c *gin.Context) {
+	filter, err := h.parseCostReportFilter(c)
 	if err != nil {
-		h.log.Warn("ошибка парсинга start_date", slog.String("date", startDateStr), slog.String("error", err.Error()))
-		newErrorResponse(c, http.StatusBadRequest, "Неверный формат start_date. Ожидается MM-YYYY")
+		h.fail(c, err)
 		return
 	}
 
-	endDate, err := parseDate(endDateStr)
+	// Вызываем слой сервис
+	total, err := h.services.GetTotalCost(c.Request.Context(), filter)
 	if err != nil {
-		h.log.Warn("ошибка парсинга end_date", slog.String("date", endDateStr), slog.String("error", err.Error()))
-		newErrorResponse(c, http.StatusBadRequest, "Неверный формат end_date. Ожидается MM-YYYY")
+		h.fail(c, err)
 		return
 	}
 
-	if startDate.After(endDate) {
-		h.log.Warn("start_date после end_date")
-		newErrorResponse(c, http.StatusBadRequest, "start_date не может быть после end_date")
+	c.JSON(http.StatusOK, gin.H{"total_cost": total, "currency": filter.Currency})
+}
+
+// GetCostBreakdown - стоимость подписок за период с разбивкой по месяцам и сервисам
+//
+//	@Summary		Разбивка стоимости подписок по месяцам и сервисам
+//	@Description	Получить стоимость подписок пользователя за выбранный период в виде матрицы: строки - сервисы, столбцы - месяцы.
+//	@Description	Дополнительно возвращаются итоги по строкам, столбцам и за весь период
+//	@Tags			subscriptions
+//	@Produce		json
+//	@Param			user_id			query		string			false	"UUID пользователя, по умолчанию - из токена"
+//	@Param			service_name	query		string			false	"Название подписки"
+//	@Param			start_date		query		string			true	"Начало периода: дата YYYY-MM-DD, месяц MM-YYYY или момент времени RFC 3339 (дата в часовом поясе tz)"
+//	@Param			end_date		query		string			true	"Конец периода включительно: дата YYYY-MM-DD, месяц MM-YYYY или момент времени RFC 3339 (дата в часовом поясе tz)"
+//	@Param			currency		query		string			false	"Валюта отчета (ISO 4217), суммы пересчитываются по загруженным курсам"	default(RUB)
+//	@Param			proration		query		string			false	"Пропорциональный расчет: none - списания целиком, daily - по дням активности"	Enums(none, daily)	default(none)
+//	@Param			date_format	query		string	false	"Формат дат в ответе"	Enums(date, month, datetime)	default(date)
+//	@Param			tz			query		string	false	"Часовой пояс IANA для дат в виде момента времени, текущей даты и времени в ответе, по умолчанию - из claim zoneinfo токена или UTC"
+//	@Success		200				{object}	costBreakdownView
+//	@Failure		400				{object}	domain.Problem	"Неверные параметры"
+//	@Failure		401				{object}	domain.Problem	"Требуется аутентификация"
+//	@Failure		403				{object}	domain.Problem	"Доступ запрещен"
+//	@Failure		422				{object}	domain.Problem	"Не найден курс валюты"
+//	@Failure		429				{object}	domain.Problem	"Слишком много запросов"
+//	@Failure		500				{object}	domain.Problem	"Внутренняя ошибка сервера"
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+//	@Router			/subscriptions/cost-breakdown [get]
+func (h *Handler) getCostBreakdown(c *gin.Context) {
+	filter, err := h.parseCostReportFilter(c)
+	if err != nil {
+		h.fail(c, err)
 		return
 	}
 
 	// Вызываем слой сервис
-	total, err := h.services.GetTotalCost(c.Request.Context(), userID, serviceName, startDate, endDate)
+	breakdown, err := h.services.GetCostBreakdown(c.Request.Context(), filter)
 	if err != nil {
-		h.log.Error("ошибка при подсчете стоимости", slog.String("error", err.Error()))
-		newErrorResponse(c, http.StatusInternalServerError, "Внутренняя ошибка сервера")
+		h.fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"total_cost": total})
+	c.JSON(http.StatusOK, dateFormatOf(c).costBreakdown(breakdown))
+}
+
+// Параметры отчета о стоимости
+type costReportQuery struct {
+	UserID      string `form:"user_id" binding:"omitempty,uuid"`
+	ServiceName string `form:"service_name" binding:"omitempty,max=255"`
+	StartDate   string `form:"start_date" binding:"required"` // Дата YYYY-MM-DD, месяц MM-YYYY (его первый день) или момент времени RFC 3339 (дата в часовом поясе tz)
+	EndDate     string `form:"end_date" binding:"required"`   // Дата YYYY-MM-DD, месяц MM-YYYY (его последний день) или момент времени RFC 3339 (дата в часовом поясе tz), включительно
+	Currency    string `form:"currency" binding:"omitempty,iso4217"`
+	Proration   string `form:"proration" binding:"omitempty,oneof=none daily"`
+}
+
+// Чтение и проверка параметров отчета о стоимости.
+// Ошибка валидации содержит все нарушенные параметры
+func (h *Handler) parseCostReportFilter(c *gin.Context) (domain.CostReportFilter, error) {
+	var query costReportQuery
+
+	if err := decodeQuery(c, &query); err != nil {
+		return domain.CostReportFilter{}, err
+	}
+	query.Currency = strings.ToUpper(query.Currency)
+
+	errs := fieldErrors{loc: domain.LocationFromContext(c.Request.Context())}
+
+	errs.check(query)
+	startDate := errs.date("start_date", query.StartDate)
+	endDate := errs.endDate("end_date", query.EndDate)
+	errs.notBefore("end_date", &endDate, "start_date", startDate)
+
+	if err := errs.err(); err != nil {
+		return domain.CostReportFilter{}, err
+	}
+
+	if query.Currency == "" {
+		query.Currency = domain.DefaultCurrency
+	}
+
+	return domain.CostReportFilter{
+		// Пользователь из query params, по умолчанию - сам аутентифицированный пользователь
+		UserID:      h.requestedUser(c),
+		ServiceName: query.ServiceName,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Currency:    query.Currency,
+		Proration:   domain.Proration(query.Proration),
+	}, nil
 }
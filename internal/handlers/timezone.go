@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Неизвестный часовой пояс
+var errUnknownTimeZone = errors.New("неизвестный часовой пояс")
+
+// Middleware выбора часового пояса запроса.
+//
+// Пояс берется из параметра tz, затем из claim zoneinfo токена пользователя, по умолчанию - UTC.
+// Неизвестный пояс в параметре - ошибка запроса, в токене - игнорируется
+func (h *Handler) timeZone(c *gin.Context) {
+	loc := time.UTC
+
+	if name := c.Query("tz"); name != "" {
+		l, err := loadLocation(name)
+		if err != nil {
+			h.fail(c, fieldError("tz", "timezone", ""))
+			return
+		}
+		loc = l
+	} else if principal, _ := domain.PrincipalFromContext(c.Request.Context()); principal.TimeZone != "" {
+		l, err := loadLocation(principal.TimeZone)
+		if err != nil {
+			h.log.Warn("неизвестный часовой пояс пользователя", slog.String("user_id", principal.UserID), slog.String("tz", principal.TimeZone))
+		} else {
+			loc = l
+		}
+	}
+
+	c.Request = c.Request.WithContext(domain.WithLocation(c.Request.Context(), loc))
+
+	c.Next()
+}
+
+// Загрузка часового пояса по имени IANA.
+// Local не принимается: пояс сервера не должен влиять на ответ
+func loadLocation(name string) (*time.Location, error) {
+	if name == "Local" {
+		return nil, errUnknownTimeZone
+	}
+	return time.LoadLocation(name)
+}
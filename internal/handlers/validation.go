@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+	"github.com/levinOo/go-crudl-task/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+)
+
+// Регистрация имен полей выполняется один раз на процесс
+var registerFieldNamesOnce sync.Once
+
+// Имена полей в ошибках валидации берутся из тегов json и form,
+// чтобы клиент видел их так же, как передавал
+func registerFieldNames() {
+	registerFieldNamesOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	})
+}
+
+// Ошибка валидации одного поля
+func fieldError(field, code, param string) error {
+	return domain.NewValidationError(domain.FieldError{Field: field, Code: code, Param: param})
+}
+
+// Ошибка формата даты в поле
+func dateFieldError(field string) error {
+	return fieldError(field, "date", dateFormats)
+}
+
+// Ошибки полей, накопленные при проверке запроса.
+// Проверка не останавливается на первой ошибке, клиент получает все нарушения сразу
+type fieldErrors struct {
+	fields []domain.FieldError
+	loc    *time.Location // Часовой пояс для дат, заданных моментом времени
+}
+
+// Добавление ошибки поля
+func (e *fieldErrors) add(field, code, param string) {
+	e.fields = append(e.fields, domain.FieldError{Field: field, Code: code, Param: param})
+}
+
+// У поля уже есть ошибка
+func (e *fieldErrors) has(field string) bool {
+	for _, f := range e.fields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Проверка структуры запроса по правилам из тегов binding
+func (e *fieldErrors) check(obj any) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(binding.Validator.ValidateStruct(obj), &validationErrors) {
+		e.fields = append(e.fields, validationFields(validationErrors)...)
+	}
+}
+
+// Разбор даты поля в формате YYYY-MM-DD, MM-YYYY или момента времени RFC 3339
+// (дата в часовом поясе запроса). Месяц означает его первый день.
+// Если у поля уже есть ошибка (например, оно не заполнено), дата не разбирается
+func (e *fieldErrors) date(field, value string) time.Time {
+	if e.has(field) {
+		return time.Time{}
+	}
+
+	t, _, err := parseDate(value, e.loc)
+	if err != nil {
+		e.add(field, "date", dateFormats)
+	}
+	return t
+}
+
+// Разбор даты окончания периода, включительно. Месяц означает его последний день
+func (e *fieldErrors) endDate(field, value string) time.Time {
+	if e.has(field) {
+		return time.Time{}
+	}
+
+	t, month, err := parseDate(value, e.loc)
+	if err != nil {
+		e.add(field, "date", dateFormats)
+	}
+	if month {
+		t = lastDayOfMonth(t)
+	}
+	return t
+}
+
+// Разбор необязательной даты поля, nil - дата не передана
+func (e *fieldErrors) optionalDate(field string, value *string) *time.Time {
+	if value == nil {
+		return nil
+	}
+
+	t := e.date(field, *value)
+	return &t
+}
+
+// Разбор необязательной даты окончания периода, nil - дата не передана
+func (e *fieldErrors) optionalEndDate(field string, value *string) *time.Time {
+	if value == nil {
+		return nil
+	}
+
+	t := e.endDate(field, *value)
+	return &t
+}
+
+// Проверка, что дата поля field не раньше даты поля startField
+func (e *fieldErrors) notBefore(field string, date *time.Time, startField string, start time.Time) {
+	if date != nil && !e.has(field) && !e.has(startField) && date.Before(start) {
+		e.add(field, "gtefield", startField)
+	}
+}
+
+// Ошибка валидации со всеми накопленными ошибками полей, nil - ошибок нет
+func (e *fieldErrors) err() error {
+	if len(e.fields) == 0 {
+		return nil
+	}
+	return domain.NewValidationError(e.fields...)
+}
+
+// Чтение тела запроса в JSON без проверки правил.
+// Правила проверяются отдельно вместе с разбором дат, чтобы собрать все ошибки полей
+func decodeJSON(body []byte, obj any) error {
+	if err := json.Unmarshal(body, obj); err != nil {
+		return bindingError(err, errInvalidBody)
+	}
+	return nil
+}
+
+// Чтение тела запроса в JSON без проверки правил
+func readJSON(c *gin.Context, obj any) error {
+	body, err := c.GetRawData()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errInvalidBody, err)
+	}
+	return decodeJSON(body, obj)
+}
+
+// Чтение параметров запроса без проверки правил
+func decodeQuery(c *gin.Context, obj any) error {
+	if err := binding.MapFormWithTag(obj, c.Request.URL.Query(), "form"); err != nil {
+		return bindingError(err, errInvalidQuery)
+	}
+	return nil
+}
+
+// Ошибка чтения тела или параметров запроса.
+// Нарушения правил валидации и неверные типы значений превращаются в ошибки по полям,
+// остальные ошибки оборачиваются в invalid
+func bindingError(err error, invalid error) error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return domain.NewValidationError(validationFields(validationErrors)...)
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		return fieldError(typeError.Field, "type", typeName(typeError.Type))
+	}
+
+	return fmt.Errorf("%w: %w", invalid, err)
+}
+
+// Ошибки полей по нарушениям правил валидатора
+func validationFields(validationErrors validator.ValidationErrors) []domain.FieldError {
+	fields := make([]domain.FieldError, len(validationErrors))
+	for i, fe := range validationErrors {
+		fields[i] = domain.FieldError{
+			Field: fieldPath(fe),
+			Code:  ruleCode(fe),
+			Param: fe.Param(),
+		}
+	}
+	return fields
+}
+
+// Путь к полю без имени структуры запроса, например scopes[0]
+func fieldPath(fe validator.FieldError) string {
+	_, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return path
+}
+
+// Код нарушенного правила валидации.
+// Для строк и массивов min и max ограничивают длину, поэтому получают отдельные коды
+func ruleCode(fe validator.FieldError) string {
+	tag := fe.Tag()
+
+	if (tag == "min" || tag == "max") && (fe.Kind() == reflect.String || fe.Kind() == reflect.Slice) {
+		return tag + "_length"
+	}
+
+	return tag
+}
+
+// Сообщения об ошибках полей на языке lang.
+// Сообщение выбирается по коду правила, для неизвестных правил - общее
+func localizeFields(lang language.Tag, fields []domain.FieldError) []domain.FieldError {
+	localized := make([]domain.FieldError, len(fields))
+
+	for i, field := range fields {
+		key := "field." + field.Code
+		if i18n.Message(lang, key) == key {
+			key = "field.invalid"
+		}
+
+		if field.Param == "" {
+			field.Message = i18n.Message(lang, key)
+		} else {
+			field.Message = i18n.Message(lang, key, strings.ReplaceAll(field.Param, " ", ", "))
+		}
+		localized[i] = field
+	}
+
+	return localized
+}
+
+// Ожидаемый тип значения JSON
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
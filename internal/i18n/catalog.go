@@ -0,0 +1,126 @@
+package i18n
+
+import "golang.org/x/text/language"
+
+// Каталог сообщений по языкам.
+//
+// Ключи:
+//   - problem.<код> - заголовок ошибки API с этим кодом;
+//   - detail.<имя> - пояснение к конкретному случаю ошибки;
+//   - field.<правило> - сообщение об ошибке поля, параметр правила подставляется через %s
+var catalog = map[language.Tag]map[string]string{
+	language.Russian: {
+		"problem.unauthenticated":              "Требуется аутентификация",
+		"problem.invalid_credentials":          "Неверный токен доступа",
+		"problem.forbidden":                    "Доступ запрещен",
+		"problem.insufficient_scope":           "У API ключа нет области доступа",
+		"problem.api_key_not_allowed":          "Маршрут недоступен по API ключу",
+		"problem.tenant_mismatch":              "Доступ к организации запрещен",
+		"problem.tenant_required":              "Организация запроса не определена",
+		"problem.validation_failed":            "Неверные данные запроса",
+		"problem.invalid_body":                 "Неверное тело запроса",
+		"problem.invalid_query":                "Неверные параметры запроса",
+		"problem.idempotency_key_too_long":     "Ключ идемпотентности не может быть длиннее 255 символов",
+		"problem.invalid_period":               "Дата окончания не может быть раньше даты начала",
+		"problem.invalid_billing_period":       "Неверный расчетный период. Ожидается weekly, monthly, quarterly или yearly",
+		"problem.invalid_currency":             "Неверный код валюты. Ожидается код ISO 4217, например RUB",
+		"problem.effective_from_without_price": "effective_from можно передать только вместе с price",
+		"problem.invalid_proration":            "Неверный режим пропорционального расчета. Ожидается none или daily",
+		"problem.invalid_status":               "Неверный начальный статус подписки. Ожидается active или trial, с trial_end_date - только trial",
+		"problem.invalid_status_transition":    "Действие недоступно в текущем статусе подписки",
+		"problem.invalid_cursor":               "Неверный курсор пагинации",
+		"problem.invalid_sort":                 "Неверное поле сортировки",
+		"problem.invalid_limit":                "Неверный размер страницы",
+		"problem.invalid_scope":                "Неизвестная область доступа API ключа",
+		"problem.invalid_rates_file":           "Неверный файл курсов валют",
+		"problem.subscription_not_found":       "Подписка не найдена",
+		"problem.api_key_not_found":            "API ключ не найден",
+		"problem.idempotency_in_progress":      "Запрос с этим ключом идемпотентности еще выполняется",
+		"problem.version_mismatch":             "Версия подписки не совпадает с If-Match",
+		"problem.idempotency_key_reused":       "Ключ идемпотентности уже использован с другим телом запроса",
+		"problem.exchange_rate_not_found":      "Не найден курс валюты для пересчета стоимости. Загрузите курсы валют",
+		"problem.rate_limited":                 "Слишком много запросов",
+		"problem.internal":                     "Внутренняя ошибка сервера",
+
+		"detail.not_in_trash":         "Подписка не найдена в корзине",
+		"detail.history_not_found":    "История подписки не найдена",
+		"detail.scope_required":       "Нужна область доступа %s",
+		"detail.if_match_unsupported": "Неподдерживаемое значение If-Match",
+		"detail.rates_file":           "Не удалось разобрать файл: %s",
+
+		"field.required":   "Обязательное поле",
+		"field.oneof":      "Допустимые значения: %s",
+		"field.iso4217":    "Ожидается код валюты ISO 4217, например RUB",
+		"field.uuid":       "Ожидается UUID",
+		"field.date":       "Ожидается дата в одном из форматов: %s",
+		"field.timezone":   "Ожидается часовой пояс IANA, например Europe/Moscow",
+		"field.type":       "Неверный тип значения, ожидается %s",
+		"field.min":        "Не меньше %s",
+		"field.max":        "Не больше %s",
+		"field.min_length": "Длина не меньше %s",
+		"field.max_length": "Длина не больше %s",
+		"field.gte":        "Не меньше %s",
+		"field.gt":         "Больше %s",
+		"field.lte":        "Не больше %s",
+		"field.lt":         "Меньше %s",
+		"field.gtefield":   "Не раньше %s",
+		"field.invalid":    "Неверное значение",
+	},
+	language.English: {
+		"problem.unauthenticated":              "Authentication required",
+		"problem.invalid_credentials":          "Invalid access token",
+		"problem.forbidden":                    "Access denied",
+		"problem.insufficient_scope":           "The API key lacks the required scope",
+		"problem.api_key_not_allowed":          "This route is not available with an API key",
+		"problem.tenant_mismatch":              "Access to the tenant is denied",
+		"problem.tenant_required":              "The request tenant could not be determined",
+		"problem.validation_failed":            "Invalid request data",
+		"problem.invalid_body":                 "Invalid request body",
+		"problem.invalid_query":                "Invalid query parameters",
+		"problem.idempotency_key_too_long":     "The idempotency key must not exceed 255 characters",
+		"problem.invalid_period":               "The end date cannot be earlier than the start date",
+		"problem.invalid_billing_period":       "Invalid billing period. Expected weekly, monthly, quarterly or yearly",
+		"problem.invalid_currency":             "Invalid currency code. Expected an ISO 4217 code such as RUB",
+		"problem.effective_from_without_price": "effective_from can only be sent together with price",
+		"problem.invalid_proration":            "Invalid proration mode. Expected none or daily",
+		"problem.invalid_status":               "Invalid initial subscription status. Expected active or trial, only trial with trial_end_date",
+		"problem.invalid_status_transition":    "The action is not available in the current subscription status",
+		"problem.invalid_cursor":               "Invalid pagination cursor",
+		"problem.invalid_sort":                 "Invalid sort field",
+		"problem.invalid_limit":                "Invalid page size",
+		"problem.invalid_scope":                "Unknown API key scope",
+		"problem.invalid_rates_file":           "Invalid exchange rates file",
+		"problem.subscription_not_found":       "Subscription not found",
+		"problem.api_key_not_found":            "API key not found",
+		"problem.idempotency_in_progress":      "A request with this idempotency key is still in progress",
+		"problem.version_mismatch":             "The subscription version does not match If-Match",
+		"problem.idempotency_key_reused":       "The idempotency key was already used with a different request body",
+		"problem.exchange_rate_not_found":      "No exchange rate found to convert the cost. Load exchange rates first",
+		"problem.rate_limited":                 "Too many requests",
+		"problem.internal":                     "Internal server error",
+
+		"detail.not_in_trash":         "Subscription not found in the trash",
+		"detail.history_not_found":    "Subscription history not found",
+		"detail.scope_required":       "Scope %s is required",
+		"detail.if_match_unsupported": "Unsupported If-Match value",
+		"detail.rates_file":           "The file could not be parsed: %s",
+
+		"field.required":   "This field is required",
+		"field.oneof":      "Allowed values: %s",
+		"field.iso4217":    "Expected an ISO 4217 currency code such as RUB",
+		"field.uuid":       "Expected a UUID",
+		"field.date":       "Expected a date in one of the formats: %s",
+		"field.timezone":   "Expected an IANA time zone such as Europe/Moscow",
+		"field.type":       "Invalid value type, expected %s",
+		"field.min":        "Must be at least %s",
+		"field.max":        "Must be at most %s",
+		"field.min_length": "Length must be at least %s",
+		"field.max_length": "Length must be at most %s",
+		"field.gte":        "Must be at least %s",
+		"field.gt":         "Must be greater than %s",
+		"field.lte":        "Must be at most %s",
+		"field.lt":         "Must be less than %s",
+		"field.gtefield":   "Must not be earlier than %s",
+		"field.invalid":    "Invalid value",
+	},
+}
@@ -0,0 +1,63 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+// Поддерживаемые языки. Первый язык используется, если подходящего нет
+var supported = []language.Tag{language.Russian, language.English}
+
+// Подбор языка по предпочтениям клиента
+var matcher = language.NewMatcher(supported)
+
+// Язык по значению заголовка Accept-Language.
+// Пустой или неверный заголовок дает язык по умолчанию
+func Negotiate(acceptLanguage string) language.Tag {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return supported[0]
+	}
+
+	return supported[index]
+}
+
+// Сообщение из каталога на языке lang.
+// Если перевода нет, берется сообщение на языке по умолчанию, а если нет и его - сам ключ.
+// Аргументы подставляются в сообщение через fmt.Sprintf
+func Message(lang language.Tag, key string, args ...any) string {
+	text, ok := catalog[lang][key]
+	if !ok {
+		text, ok = catalog[supported[0]][key]
+	}
+	if !ok {
+		return key
+	}
+
+	if len(args) == 0 {
+		return text
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+// Ключ языка в контексте
+type languageKey struct{}
+
+// Контекст с языком ответа
+func WithLanguage(ctx context.Context, lang language.Tag) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// Язык ответа из контекста, по умолчанию - язык по умолчанию
+func LanguageFromContext(ctx context.Context) language.Tag {
+	lang, ok := ctx.Value(languageKey{}).(language.Tag)
+	if !ok {
+		return supported[0]
+	}
+	return lang
+}
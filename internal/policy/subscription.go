@@ -0,0 +1,242 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Интерфейс сервиса подписок
+type SubscriptionService interface {
+	Create(ctx context.Context, sub domain.Subscription) (domain.Subscription, error)
+	Get(ctx context.Context, id string) (domain.Subscription, error)
+	Owner(ctx context.Context, id string) (string, error)
+	Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) (domain.Subscription, error)
+	Replace(ctx context.Context, id string, sub domain.Subscription, ifVersion *int64) (domain.Subscription, error)
+	Delete(ctx context.Context, id string, ifVersion *int64) error
+	List(ctx context.Context, filter domain.ListSubscriptionsFilter) (domain.SubscriptionPage, error)
+	Restore(ctx context.Context, id string) (domain.Subscription, error)
+	ChangeStatus(ctx context.Context, id string, action domain.SubscriptionAction, ifVersion *int64) (domain.Subscription, error)
+	Purge(ctx context.Context) (int64, error)
+	History(ctx context.Context, id string) ([]domain.SubscriptionEvent, error)
+	Prices(ctx context.Context, id string) ([]domain.SubscriptionPrice, error)
+	GetTotalCost(ctx context.Context, filter domain.CostReportFilter) (int, error)
+	GetCostBreakdown(ctx context.Context, filter domain.CostReportFilter) (domain.CostBreakdown, error)
+}
+
+// Операции над подписками, для которых проверяются права
+type operation string
+
+const (
+	opRead   operation = "read"   // Чтение подписок, их истории и цен
+	opWrite  operation = "write"  // Создание, изменение, удаление, восстановление и смена статуса
+	opReport operation = "report" // Отчеты о стоимости
+	opPurge  operation = "purge"  // Очистка корзины
+)
+
+// Права ролей: может ли роль выполнить операцию над чужими подписками (any)
+// и над своими (own)
+var rolePermissions = map[domain.Role]map[operation]struct{ own, any bool }{
+	domain.RoleUser: {
+		opRead:   {own: true},
+		opWrite:  {own: true},
+		opReport: {own: true},
+	},
+	domain.RoleSupport: {
+		opRead:   {own: true, any: true},
+		opReport: {own: true},
+	},
+	domain.RoleAdmin: {
+		opRead:   {own: true, any: true},
+		opWrite:  {own: true, any: true},
+		opReport: {own: true, any: true},
+		opPurge:  {own: true, any: true},
+	},
+}
+
+// Политика доступа к подпискам.
+// Оборачивает сервис подписок и перед каждой операцией проверяет роль пользователя
+// из контекста и владельца подписки. Отказ возвращается как domain.ErrForbidden
+type SubscriptionPolicy struct {
+	next SubscriptionService
+	log  *slog.Logger
+}
+
+// Функция конструктор политики доступа к подпискам
+func NewSubscriptionPolicy(next SubscriptionService, log *slog.Logger) *SubscriptionPolicy {
+	return &SubscriptionPolicy{
+		next: next,
+		log:  log,
+	}
+}
+
+// Проверка права на операцию над подписками пользователя owner.
+// Пустой owner - операция не относится к конкретному пользователю
+func (p *SubscriptionPolicy) authorize(ctx context.Context, op operation, owner string) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+
+	permission := rolePermissions[principal.Role][op]
+	allowed := ok && (permission.any || (permission.own && owner != "" && owner == principal.UserID))
+	if allowed {
+		return nil
+	}
+
+	p.log.Warn("доступ запрещен политикой",
+		slog.String("user_id", principal.UserID),
+		slog.String("role", string(principal.Role)),
+		slog.String("operation", string(op)),
+		slog.String("owner", owner),
+	)
+
+	return fmt.Errorf("%w: роль %q не может выполнить %s", domain.ErrForbidden, principal.Role, op)
+}
+
+// Проверка права на операцию над подпиской id
+func (p *SubscriptionPolicy) authorizeSubscription(ctx context.Context, op operation, id string) error {
+	// Чужие подписки роли видны и так - владельца не запрашиваем
+	principal, _ := domain.PrincipalFromContext(ctx)
+	if rolePermissions[principal.Role][op].any {
+		return nil
+	}
+
+	owner, err := p.next.Owner(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return p.authorize(ctx, op, owner)
+}
+
+// Проверка права на создание подписки без ее создания.
+// Нужна до повтора сохраненного ответа на запрос с ключом идемпотентности
+func (p *SubscriptionPolicy) AuthorizeCreate(ctx context.Context, sub domain.Subscription) error {
+	return p.authorize(ctx, opWrite, sub.UserID)
+}
+
+// Создание подписки
+func (p *SubscriptionPolicy) Create(ctx context.Context, sub domain.Subscription) (domain.Subscription, error) {
+	if err := p.authorize(ctx, opWrite, sub.UserID); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return p.next.Create(ctx, sub)
+}
+
+// Получение подписки
+func (p *SubscriptionPolicy) Get(ctx context.Context, id string) (domain.Subscription, error) {
+	if err := p.authorizeSubscription(ctx, opRead, id); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return p.next.Get(ctx, id)
+}
+
+// Получение владельца подписки
+func (p *SubscriptionPolicy) Owner(ctx context.Context, id string) (string, error) {
+	if err := p.authorizeSubscription(ctx, opRead, id); err != nil {
+		return "", err
+	}
+
+	return p.next.Owner(ctx, id)
+}
+
+// Частичное обновление подписки
+func (p *SubscriptionPolicy) Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) (domain.Subscription, error) {
+	if err := p.authorizeSubscription(ctx, opWrite, id); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return p.next.Update(ctx, id, input)
+}
+
+// Полная замена подписки
+func (p *SubscriptionPolicy) Replace(ctx context.Context, id string, sub domain.Subscription, ifVersion *int64) (domain.Subscription, error) {
+	if err := p.authorizeSubscription(ctx, opWrite, id); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return p.next.Replace(ctx, id, sub, ifVersion)
+}
+
+// Удаление подписки в корзину
+func (p *SubscriptionPolicy) Delete(ctx context.Context, id string, ifVersion *int64) error {
+	if err := p.authorizeSubscription(ctx, opWrite, id); err != nil {
+		return err
+	}
+
+	return p.next.Delete(ctx, id, ifVersion)
+}
+
+// Получение списка подписок пользователя
+func (p *SubscriptionPolicy) List(ctx context.Context, filter domain.ListSubscriptionsFilter) (domain.SubscriptionPage, error) {
+	if err := p.authorize(ctx, opRead, filter.UserID); err != nil {
+		return domain.SubscriptionPage{}, err
+	}
+
+	return p.next.List(ctx, filter)
+}
+
+// Восстановление подписки из корзины
+func (p *SubscriptionPolicy) Restore(ctx context.Context, id string) (domain.Subscription, error) {
+	if err := p.authorizeSubscription(ctx, opWrite, id); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return p.next.Restore(ctx, id)
+}
+
+// Смена статуса подписки
+func (p *SubscriptionPolicy) ChangeStatus(ctx context.Context, id string, action domain.SubscriptionAction, ifVersion *int64) (domain.Subscription, error) {
+	if err := p.authorizeSubscription(ctx, opWrite, id); err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return p.next.ChangeStatus(ctx, id, action, ifVersion)
+}
+
+// Очистка корзины
+func (p *SubscriptionPolicy) Purge(ctx context.Context) (int64, error) {
+	if err := p.authorize(ctx, opPurge, ""); err != nil {
+		return 0, err
+	}
+
+	return p.next.Purge(ctx)
+}
+
+// Получение истории изменений подписки
+func (p *SubscriptionPolicy) History(ctx context.Context, id string) ([]domain.SubscriptionEvent, error) {
+	if err := p.authorizeSubscription(ctx, opRead, id); err != nil {
+		return nil, err
+	}
+
+	return p.next.History(ctx, id)
+}
+
+// Получение истории цен подписки
+func (p *SubscriptionPolicy) Prices(ctx context.Context, id string) ([]domain.SubscriptionPrice, error) {
+	if err := p.authorizeSubscription(ctx, opRead, id); err != nil {
+		return nil, err
+	}
+
+	return p.next.Prices(ctx, id)
+}
+
+// Получение общей стоимости подписок пользователя
+func (p *SubscriptionPolicy) GetTotalCost(ctx context.Context, filter domain.CostReportFilter) (int, error) {
+	if err := p.authorize(ctx, opReport, filter.UserID); err != nil {
+		return 0, err
+	}
+
+	return p.next.GetTotalCost(ctx, filter)
+}
+
+// Получение стоимости подписок пользователя по месяцам и сервисам
+func (p *SubscriptionPolicy) GetCostBreakdown(ctx context.Context, filter domain.CostReportFilter) (domain.CostBreakdown, error) {
+	if err := p.authorize(ctx, opReport, filter.UserID); err != nil {
+		return domain.CostBreakdown{}, err
+	}
+
+	return p.next.GetCostBreakdown(ctx, filter)
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/levinOo/go-crudl-task/internal/db"
+	"github.com/levinOo/go-crudl-task/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Структура репозитория API ключей
+type APIKeyRepository struct {
+	pg *db.Postgres
+}
+
+// Функция конструктор
+func NewAPIKeyRepository(pg *db.Postgres) *APIKeyRepository {
+	return &APIKeyRepository{pg: pg}
+}
+
+// Колонки API ключа в порядке полей scanAPIKey
+const apiKeyColumns = `
+	id, user_id, tenant_id, name, scopes, created_at, rotated_at, last_used_at, revoked_at, salt, secret_hash
+`
+
+// Сканирование строки с колонками apiKeyColumns
+func scanAPIKey(row pgx.Row) (domain.APIKey, error) {
+	var (
+		key    domain.APIKey
+		scopes []string
+	)
+
+	err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.TenantID,
+		&key.Name,
+		&scopes,
+		&key.CreatedAt,
+		&key.RotatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.Salt,
+		&key.SecretHash,
+	)
+
+	key.Scopes = make([]domain.Scope, len(scopes))
+	for i, scope := range scopes {
+		key.Scopes[i] = domain.Scope(scope)
+	}
+
+	return key, err
+}
+
+// Области доступа в виде массива для Postgres
+func scopesArray(scopes []domain.Scope) []string {
+	result := make([]string, len(scopes))
+	for i, scope := range scopes {
+		result[i] = string(scope)
+	}
+
+	return result
+}
+
+// Создание API ключа
+func (r *APIKeyRepository) Create(ctx context.Context, key domain.APIKey) (domain.APIKey, error) {
+	query := `
+		INSERT INTO api_keys (user_id, tenant_id, name, scopes, salt, secret_hash)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + apiKeyColumns
+
+	created, err := scanAPIKey(r.pg.Conn(ctx).QueryRow(ctx, query,
+		key.UserID,
+		key.TenantID,
+		key.Name,
+		scopesArray(key.Scopes),
+		key.Salt,
+		key.SecretHash,
+	))
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("Ошибка при создании API ключа: %w", err)
+	}
+
+	return created, nil
+}
+
+// Получение API ключа по ID, в том числе отозванного.
+// Ищется во всех организациях: организация запроса определяется самим ключом
+func (r *APIKeyRepository) Get(ctx context.Context, id string) (domain.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE id = $1
+	`
+
+	key, err := scanAPIKey(r.pg.Conn(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.APIKey{}, domain.ErrAPIKeyNotFound
+		}
+		return domain.APIKey{}, fmt.Errorf("Ошибка при получении API ключа: %w", err)
+	}
+
+	return key, nil
+}
+
+// Получение API ключей пользователя
+func (r *APIKeyRepository) List(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE tenant_id = $1
+		AND user_id = $2
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.pg.Conn(ctx).Query(ctx, query, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при получении списка API ключей: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]domain.APIKey, 0)
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("Ошибка при сканировании списка API ключей: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Ошибка при сканировании списка API ключей: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Смена секрета действующего API ключа пользователя
+func (r *APIKeyRepository) Rotate(ctx context.Context, userID, id string, salt, secretHash []byte) (domain.APIKey, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	query := `
+		UPDATE api_keys
+		SET salt = $4, secret_hash = $5, rotated_at = now()
+		WHERE tenant_id = $1
+		AND id = $2
+		AND user_id = $3
+		AND revoked_at IS NULL
+		RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(r.pg.Conn(ctx).QueryRow(ctx, query, tenantID, id, userID, salt, secretHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.APIKey{}, domain.ErrAPIKeyNotFound
+		}
+		return domain.APIKey{}, fmt.Errorf("Ошибка при смене секрета API ключа: %w", err)
+	}
+
+	return key, nil
+}
+
+// Отзыв действующего API ключа пользователя
+func (r *APIKeyRepository) Revoke(ctx context.Context, userID, id string) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE api_keys
+		SET revoked_at = now()
+		WHERE tenant_id = $1
+		AND id = $2
+		AND user_id = $3
+		AND revoked_at IS NULL
+	`
+
+	result, err := r.pg.Conn(ctx).Exec(ctx, query, tenantID, id, userID)
+	if err != nil {
+		return fmt.Errorf("Ошибка при отзыве API ключа: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// Отметка об использовании API ключа.
+// Время обновляется не чаще раза в минуту, чтобы не писать в базу на каждый запрос
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = now()
+		WHERE id = $1
+		AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`
+
+	if _, err := r.pg.Conn(ctx).Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("Ошибка при обновлении времени использования API ключа: %w", err)
+	}
+
+	return nil
+}
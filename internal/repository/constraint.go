@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Код ошибки PostgreSQL при нарушении ограничения CHECK
+const checkViolationCode = "23514"
+
+// Поля, которые проверяют ограничения CHECK таблиц подписок.
+// Правила совпадают с проверкой запросов в хендлерах
+var checkConstraintFields = map[string]domain.FieldError{
+	"subscriptions_service_name_check":   {Field: "service_name", Code: "required"},
+	"subscriptions_price_check":          {Field: "price", Code: "gt", Param: "0"},
+	"subscriptions_user_id_check":        {Field: "user_id", Code: "uuid"},
+	"subscriptions_currency_check":       {Field: "currency", Code: "iso4217"},
+	"subscriptions_billing_period_check": {Field: "billing_period", Code: "oneof", Param: "weekly monthly quarterly yearly"},
+	"subscriptions_trial_end_date_check": {Field: "trial_end_date", Code: "gtefield", Param: "start_date"},
+	"subscription_prices_price_check":    {Field: "price", Code: "gt", Param: "0"},
+}
+
+// Перевод нарушения ограничения CHECK в ошибку предметной области.
+// Остальные ошибки возвращаются без изменений
+func checkViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != checkViolationCode {
+		return err
+	}
+
+	if pgErr.ConstraintName == "subscriptions_period_check" {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidPeriod, err)
+	}
+
+	if field, ok := checkConstraintFields[pgErr.ConstraintName]; ok {
+		return fmt.Errorf("%w: %w", domain.NewValidationError(field), err)
+	}
+
+	return err
+}
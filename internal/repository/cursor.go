@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Содержимое курсора пагинации списка подписок
+type cursorPayload struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d,omitempty"`
+	Value  string `json:"v"`
+	ID     string `json:"id"`
+}
+
+// Формат id подписки в курсоре
+var cursorIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// Разобранный курсор: значение колонки сортировки и id последней строки страницы
+type cursor struct {
+	value any
+	id    string
+}
+
+// Кодирование курсора по последней подписке страницы.
+// Для клиента курсор непрозрачен: это base64 от JSON
+func encodeCursor(sub domain.Subscription, sortBy string, desc bool) string {
+	payload := cursorPayload{
+		SortBy: sortBy,
+		Desc:   desc,
+		ID:     sub.ID,
+	}
+
+	switch sortBy {
+	case domain.SortByStartDate:
+		payload.Value = sub.StartDate.Format(time.RFC3339Nano)
+	case domain.SortByPrice:
+		payload.Value = strconv.Itoa(sub.Price)
+	case domain.SortByServiceName:
+		payload.Value = sub.ServiceName
+	}
+
+	data, _ := json.Marshal(payload)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Декодирование курсора. Курсор, выданный для другой сортировки, считается неверным
+func decodeCursor(raw, sortBy string, desc bool) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor{}, domain.ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return cursor{}, domain.ErrInvalidCursor
+	}
+
+	if payload.SortBy != sortBy || payload.Desc != desc || !cursorIDPattern.MatchString(payload.ID) {
+		return cursor{}, domain.ErrInvalidCursor
+	}
+
+	cur := cursor{id: payload.ID}
+
+	switch sortBy {
+	case domain.SortByStartDate:
+		t, err := time.Parse(time.RFC3339Nano, payload.Value)
+		if err != nil {
+			return cursor{}, domain.ErrInvalidCursor
+		}
+		cur.value = t
+	case domain.SortByPrice:
+		price, err := strconv.ParseInt(payload.Value, 10, 64)
+		if err != nil {
+			return cursor{}, domain.ErrInvalidCursor
+		}
+		cur.value = price
+	case domain.SortByServiceName:
+		cur.value = payload.Value
+	default:
+		return cursor{}, domain.ErrInvalidCursor
+	}
+
+	return cur, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/levinOo/go-crudl-task/internal/db"
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Структура репозитория курсов валют
+type ExchangeRateRepository struct {
+	pg *db.Postgres
+}
+
+// Функция конструктор
+func NewExchangeRateRepository(pg *db.Postgres) *ExchangeRateRepository {
+	return &ExchangeRateRepository{pg: pg}
+}
+
+// Замена всех курсов валют организации.
+// Курсы из разных файлов могут быть заданы относительно разных базовых валют,
+// поэтому старые курсы удаляются целиком. Вызывается в транзакции сервиса
+func (r *ExchangeRateRepository) ReplaceAll(ctx context.Context, rates []domain.ExchangeRate) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	conn := r.pg.Conn(ctx)
+
+	if _, err := conn.Exec(ctx, "DELETE FROM exchange_rates WHERE tenant_id = $1", tenantID); err != nil {
+		return fmt.Errorf("Ошибка при удалении курсов валют: %w", err)
+	}
+
+	query := `
+		INSERT INTO exchange_rates (tenant_id, currency, rate, updated_at)
+		VALUES ($1, $2, $3, now())
+	`
+
+	for _, rate := range rates {
+		if _, err := conn.Exec(ctx, query, tenantID, rate.Currency, rate.Rate); err != nil {
+			return fmt.Errorf("Ошибка при сохранении курса %s: %w", rate.Currency, err)
+		}
+	}
+
+	return nil
+}
+
+// Получение списка курсов валют организации
+func (r *ExchangeRateRepository) List(ctx context.Context) ([]domain.ExchangeRate, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT currency, rate, updated_at
+		FROM exchange_rates
+		WHERE tenant_id = $1
+		ORDER BY currency
+	`
+
+	rows, err := r.pg.Conn(ctx).Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при получении курсов валют: %w", err)
+	}
+	defer rows.Close()
+
+	rates := make([]domain.ExchangeRate, 0)
+
+	for rows.Next() {
+		var rate domain.ExchangeRate
+
+		if err := rows.Scan(&rate.Currency, &rate.Rate, &rate.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("Ошибка при сканировании курсов валют: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Ошибка при сканировании курсов валют: %w", err)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/levinOo/go-crudl-task/internal/db"
+	"github.com/levinOo/go-crudl-task/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Структура репозитория ключей идемпотентности
+type IdempotencyRepository struct {
+	pg *db.Postgres
+}
+
+// Функция конструктор
+func NewIdempotencyRepository(pg *db.Postgres) *IdempotencyRepository {
+	return &IdempotencyRepository{pg: pg}
+}
+
+// Резервирование ключа идемпотентности.
+//
+// Ключ уникален в пределах организации и пользователя record.UserID.
+// Если ключ свободен (или его срок истек), он сохраняется и возвращается true.
+// Иначе возвращается уже существующая запись и false.
+// Запись с истекшим сроком, которую еще не удалила фоновая очистка, занимается заново
+func (r *IdempotencyRepository) Reserve(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, err
+	}
+
+	query := `
+		INSERT INTO idempotency_keys (tenant_id, user_id, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, user_id, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			response_body = NULL,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= now()
+	`
+
+	result, err := r.pg.Conn(ctx).Exec(ctx, query, tenantID, record.UserID, record.Key, record.RequestHash, record.ExpiresAt)
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("Ошибка при сохранении ключа идемпотентности: %w", err)
+	}
+
+	if result.RowsAffected() == 1 {
+		return record, true, nil
+	}
+
+	existing, err := r.get(ctx, tenantID, record.UserID, record.Key)
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, err
+	}
+
+	return existing, false, nil
+}
+
+// Получение записи по ключу идемпотентности
+func (r *IdempotencyRepository) get(ctx context.Context, tenantID, userID, key string) (domain.IdempotencyRecord, error) {
+	query := `
+		SELECT user_id, key, request_hash, COALESCE(status_code, 0), response_body, expires_at
+		FROM idempotency_keys
+		WHERE tenant_id = $1
+		AND user_id = $2
+		AND key = $3
+	`
+
+	var record domain.IdempotencyRecord
+
+	err := r.pg.Conn(ctx).QueryRow(ctx, query, tenantID, userID, key).Scan(
+		&record.UserID,
+		&record.Key,
+		&record.RequestHash,
+		&record.StatusCode,
+		&record.Response,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		// Ключ могли освободить между вставкой и чтением - считаем, что запрос еще выполняется
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.IdempotencyRecord{UserID: userID, Key: key}, domain.ErrIdempotencyInProgress
+		}
+		return domain.IdempotencyRecord{}, fmt.Errorf("Ошибка при получении ключа идемпотентности: %w", err)
+	}
+
+	return record, nil
+}
+
+// Сохранение ответа на запрос с ключом идемпотентности.
+// Вызывается в транзакции сервиса вместе с самим запросом
+func (r *IdempotencyRepository) SaveResponse(ctx context.Context, userID, key string, statusCode int, response []byte) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $4, response_body = $5
+		WHERE tenant_id = $1
+		AND user_id = $2
+		AND key = $3
+	`
+
+	if _, err := r.pg.Conn(ctx).Exec(ctx, query, tenantID, userID, key, statusCode, response); err != nil {
+		return fmt.Errorf("Ошибка при сохранении ответа для ключа идемпотентности: %w", err)
+	}
+
+	return nil
+}
+
+// Освобождение ключа, если запрос завершился ошибкой и его можно повторить
+func (r *IdempotencyRepository) Release(ctx context.Context, userID, key string) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := "DELETE FROM idempotency_keys WHERE tenant_id = $1 AND user_id = $2 AND key = $3 AND status_code IS NULL"
+
+	if _, err := r.pg.Conn(ctx).Exec(ctx, query, tenantID, userID, key); err != nil {
+		return fmt.Errorf("Ошибка при освобождении ключа идемпотентности: %w", err)
+	}
+
+	return nil
+}
+
+// Удаление ключей организации с истекшим сроком. Возвращает число удаленных ключей
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := r.pg.Conn(ctx).Exec(ctx, "DELETE FROM idempotency_keys WHERE tenant_id = $1 AND expires_at <= now()", tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("Ошибка при удалении истекших ключей идемпотентности: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
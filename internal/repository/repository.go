@@ -2,31 +2,89 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/levinOo/go-crudl-task/internal/db"
 	"github.com/levinOo/go-crudl-task/internal/domain"
-
-	"time"
 )
 
 // Интерфейс репозитория подписок
 type SubscriptionRepo interface {
-	Create(ctx context.Context, sub domain.Subscription) (string, error)
+	Create(ctx context.Context, sub domain.Subscription) (domain.Subscription, error)
 	Get(ctx context.Context, id string) (domain.Subscription, error)
-	Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) error
-	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, userID string) ([]domain.Subscription, error)
-	GetTotalCost(ctx context.Context, userID, serviceName string, startDate, endDate time.Time) (int, error)
+	Owner(ctx context.Context, id string) (string, error)
+	Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) (domain.Subscription, error)
+	Lock(ctx context.Context, id string) (domain.Subscription, error)
+	Delete(ctx context.Context, id string, ifVersion *int64) (domain.Subscription, error)
+	List(ctx context.Context, filter domain.ListSubscriptionsFilter) (domain.SubscriptionPage, error)
+	Restore(ctx context.Context, id string) (domain.Subscription, error)
+	ChangeStatus(ctx context.Context, id string, change domain.StatusChange) (domain.Subscription, error)
+	ListStatusDue(ctx context.Context, today time.Time) ([]domain.Subscription, error)
+	Purge(ctx context.Context, before time.Time) ([]domain.Subscription, error)
+	ListPrices(ctx context.Context, id string) ([]domain.SubscriptionPrice, error)
+	ListCharges(ctx context.Context, filter domain.CostReportFilter) ([]domain.Charge, error)
+}
+
+// Интерфейс репозитория истории изменений подписок
+type SubscriptionEventRepo interface {
+	Create(ctx context.Context, event domain.SubscriptionEvent) error
+	List(ctx context.Context, subscriptionID string) ([]domain.SubscriptionEvent, error)
+}
+
+// Интерфейс репозитория API ключей
+type APIKeyRepo interface {
+	Create(ctx context.Context, key domain.APIKey) (domain.APIKey, error)
+	Get(ctx context.Context, id string) (domain.APIKey, error)
+	List(ctx context.Context, userID string) ([]domain.APIKey, error)
+	Rotate(ctx context.Context, userID, id string, salt, secretHash []byte) (domain.APIKey, error)
+	Revoke(ctx context.Context, userID, id string) error
+	TouchLastUsed(ctx context.Context, id string) error
+}
+
+// Интерфейс репозитория организаций
+type TenantRepo interface {
+	List(ctx context.Context) ([]string, error)
+}
+
+// Интерфейс выполнения операций в одной транзакции
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Интерфейс репозитория курсов валют
+type ExchangeRateRepo interface {
+	ReplaceAll(ctx context.Context, rates []domain.ExchangeRate) error
+	List(ctx context.Context) ([]domain.ExchangeRate, error)
+}
+
+// Интерфейс репозитория ключей идемпотентности
+type IdempotencyRepo interface {
+	Reserve(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error)
+	SaveResponse(ctx context.Context, userID, key string, statusCode int, response []byte) error
+	Release(ctx context.Context, userID, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
 }
 
 // Структура слоя репозиториев
 type Repositories struct {
-	Subscription SubscriptionRepo
+	Subscription      SubscriptionRepo
+	SubscriptionEvent SubscriptionEventRepo
+	ExchangeRate      ExchangeRateRepo
+	Idempotency       IdempotencyRepo
+	APIKey            APIKeyRepo
+	Tenant            TenantRepo
+	Transactor        Transactor
 }
 
 // Функция конструктор слоя репозиториев
 func NewRepositories(pg *db.Postgres) *Repositories {
 	return &Repositories{
-		Subscription: NewSubscriptionRepository(pg),
+		Subscription:      NewSubscriptionRepository(pg),
+		SubscriptionEvent: NewSubscriptionEventRepository(pg),
+		ExchangeRate:      NewExchangeRateRepository(pg),
+		Idempotency:       NewIdempotencyRepository(pg),
+		APIKey:            NewAPIKeyRepository(pg),
+		Tenant:            NewTenantRepository(pg),
+		Transactor:        pg,
 	}
 }
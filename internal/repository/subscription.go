@@ -22,48 +22,101 @@ func NewSubscriptionRepository(pg *db.Postgres) *SubscriptionRepository {
 	return &SubscriptionRepository{pg: pg}
 }
 
-// Создание подписки
-func (r *SubscriptionRepository) Create(ctx context.Context, sub domain.Subscription) (string, error) {
-	query := `
-		INSERT INTO subscriptions (service_name, price, user_id, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
-	`
+// Колонки подписки в порядке полей scanSubscription
+const subscriptionColumns = `
+	id, service_name, price, currency, billing_period, user_id,
+	start_date, end_date, status, trial_end_date, created_at, updated_at, version, deleted_at
+`
+
+// Сканирование строки с колонками subscriptionColumns
+func scanSubscription(row pgx.Row) (domain.Subscription, error) {
+	var sub domain.Subscription
 
-	var id string
+	err := row.Scan(
+		&sub.ID,
+		&sub.ServiceName,
+		&sub.Price,
+		&sub.Currency,
+		&sub.BillingPeriod,
+		&sub.UserID,
+		&sub.StartDate,
+		&sub.EndDate,
+		&sub.Status,
+		&sub.TrialEndDate,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+		&sub.Version,
+		&sub.DeletedAt,
+	)
 
-	err := r.pg.Pool.QueryRow(ctx, query,
+	return sub, err
+}
+
+// Создание подписки вместе с первой записью истории цен, действующей с месяца начала,
+// и первым периодом активности в начальном статусе.
+// Организация подписки регистрируется для фоновых задач
+func (r *SubscriptionRepository) Create(ctx context.Context, sub domain.Subscription) (domain.Subscription, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	query := `
+		WITH created AS (
+			INSERT INTO subscriptions (service_name, price, currency, billing_period, user_id, start_date, end_date, status, trial_end_date, tenant_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING *
+		), price AS (
+			INSERT INTO subscription_prices (subscription_id, effective_from, price, tenant_id)
+			SELECT id, date_trunc('month', start_date)::DATE, price, tenant_id
+			FROM created
+		), period AS (
+			INSERT INTO subscription_periods (subscription_id, status, started_at, tenant_id)
+			SELECT id, status, start_date, tenant_id
+			FROM created
+		), tenant AS (
+			INSERT INTO tenants (id)
+			SELECT tenant_id FROM created
+			ON CONFLICT (id) DO NOTHING
+		)
+		SELECT ` + subscriptionColumns + ` FROM created`
+
+	created, err := scanSubscription(r.pg.Conn(ctx).QueryRow(ctx, query,
 		sub.ServiceName,
 		sub.Price,
+		sub.Currency,
+		sub.BillingPeriod,
 		sub.UserID,
 		sub.StartDate,
 		sub.EndDate,
-	).Scan(&id)
+		sub.Status,
+		sub.TrialEndDate,
+		tenantID,
+	))
 
 	if err != nil {
-		return "", fmt.Errorf("Ошибка при создании подписки: %w", err)
+		return domain.Subscription{}, fmt.Errorf("Ошибка при создании подписки: %w", checkViolation(err))
 	}
 
-	return id, nil
+	return created, nil
 }
 
 // Получение подписки
 func (r *SubscriptionRepository) Get(ctx context.Context, id string) (domain.Subscription, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
 	query := `
-		SELECT service_name, price, user_id, start_date, end_date
+		SELECT ` + subscriptionColumns + `
 		FROM subscriptions
-		WHERE id = $1
+		WHERE tenant_id = $1
+		AND id = $2
+		AND deleted_at IS NULL
 	`
 
-	var sub domain.Subscription
-
-	err := r.pg.Pool.QueryRow(ctx, query, id).Scan(
-		&sub.ServiceName,
-		&sub.Price,
-		&sub.UserID,
-		&sub.StartDate,
-		&sub.EndDate,
-	)
+	sub, err := scanSubscription(r.pg.Conn(ctx).QueryRow(ctx, query, tenantID, id))
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -75,118 +128,776 @@ func (r *SubscriptionRepository) Get(ctx context.Context, id string) (domain.Sub
 	return sub, nil
 }
 
-// Обновление подписки
-func (r *SubscriptionRepository) Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) error {
+// Получение владельца подписки, в том числе удаленной в корзину или окончательно.
+// Для окончательно удаленной подписки владелец берется из истории изменений
+func (r *SubscriptionRepository) Owner(ctx context.Context, id string) (string, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		SELECT COALESCE(
+			(SELECT user_id FROM subscriptions WHERE tenant_id = $1 AND id = $2),
+			(
+				SELECT COALESCE(after, before)->>'user_id'
+				FROM subscription_events
+				WHERE tenant_id = $1
+				AND subscription_id = $2
+				ORDER BY id DESC
+				LIMIT 1
+			)
+		)
+	`
+
+	var owner *string
+
+	if err := r.pg.Conn(ctx).QueryRow(ctx, query, tenantID, id).Scan(&owner); err != nil {
+		return "", fmt.Errorf("Ошибка при получении владельца подписки: %w", err)
+	}
+
+	if owner == nil {
+		return "", domain.ErrSubscriptionNotFound
+	}
+
+	return *owner, nil
+}
+
+// Получение подписки с блокировкой строки до конца транзакции.
+// В отличие от Get находит и подписки в корзине
+func (r *SubscriptionRepository) Lock(ctx context.Context, id string) (domain.Subscription, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE tenant_id = $1
+		AND id = $2
+		FOR UPDATE
+	`
+
+	sub, err := scanSubscription(r.pg.Conn(ctx).QueryRow(ctx, query, tenantID, id))
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Subscription{}, domain.ErrSubscriptionNotFound
+		}
+		return domain.Subscription{}, fmt.Errorf("Ошибка при блокировке подписки: %w", err)
+	}
+
+	return sub, nil
+}
+
+// Обновление подписки.
+//
+// Новая цена сохраняется в историю цен с даты input.EffectiveFrom,
+// а в подписке остается цена, действующая на текущую дату (или первая цена истории, если все еще впереди).
+// Должно вызываться в транзакции: запись истории цен не откатывается при конфликте версий
+func (r *SubscriptionRepository) Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) (domain.Subscription, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
 	query := "UPDATE subscriptions SET "
 	args := []any{}
 	argId := 1
 
 	if input.Price != nil {
-		query += fmt.Sprintf("price = $%d, ", argId)
-		args = append(args, *input.Price)
+		priceQuery := `
+			INSERT INTO subscription_prices (subscription_id, effective_from, price, tenant_id)
+			VALUES ($1, $2::DATE, $3, $4)
+			ON CONFLICT (subscription_id, effective_from) DO UPDATE SET price = EXCLUDED.price
+		`
+
+		if _, err := r.pg.Conn(ctx).Exec(ctx, priceQuery, id, input.EffectiveFrom, *input.Price, tenantID); err != nil {
+			return domain.Subscription{}, fmt.Errorf("Ошибка при сохранении истории цен: %w", checkViolation(err))
+		}
+	}
+
+	if input.ServiceName != nil {
+		query += fmt.Sprintf("service_name = $%d, ", argId)
+		args = append(args, *input.ServiceName)
+		argId++
+	}
+	if input.Price != nil {
+		query += fmt.Sprintf(`price = COALESCE(
+			(
+				SELECT price FROM subscription_prices
+				WHERE subscription_id = $%[1]d
+				AND effective_from <= current_date
+				ORDER BY effective_from DESC
+				LIMIT 1
+			),
+			(
+				SELECT price FROM subscription_prices
+				WHERE subscription_id = $%[1]d
+				ORDER BY effective_from
+				LIMIT 1
+			)
+		), `, argId)
+		args = append(args, id)
+		argId++
+	}
+	if input.Currency != nil {
+		query += fmt.Sprintf("currency = $%d, ", argId)
+		args = append(args, *input.Currency)
+		argId++
+	}
+	if input.BillingPeriod != nil {
+		query += fmt.Sprintf("billing_period = $%d, ", argId)
+		args = append(args, *input.BillingPeriod)
+		argId++
+	}
+	if input.StartDate != nil {
+		query += fmt.Sprintf("start_date = $%d, ", argId)
+		args = append(args, *input.StartDate)
 		argId++
 	}
 	if input.EndDate != nil {
 		query += fmt.Sprintf("end_date = $%d, ", argId)
 		args = append(args, *input.EndDate)
 		argId++
+	} else if input.ClearEndDate {
+		query += "end_date = NULL, "
 	}
 
-	if len(args) == 0 {
-		return nil
+	// Обновлять нечего - возвращаем подписку как есть, но с проверкой версии
+	if argId == 1 && !input.ClearEndDate {
+		sub, err := r.Get(ctx, id)
+		if err != nil {
+			return domain.Subscription{}, err
+		}
+		if input.IfVersion != nil && sub.Version != *input.IfVersion {
+			return domain.Subscription{}, domain.ErrVersionMismatch
+		}
+		return sub, nil
 	}
 
-	query = query[:len(query)-2]
+	query += "version = version + 1"
 
-	query += fmt.Sprintf(" WHERE id = $%d", argId)
-	args = append(args, id)
+	query += fmt.Sprintf(" WHERE tenant_id = $%d AND id = $%d AND deleted_at IS NULL AND ($%d::BIGINT IS NULL OR version = $%d) RETURNING %s",
+		argId, argId+1, argId+2, argId+2, subscriptionColumns)
+	args = append(args, tenantID, id, input.IfVersion)
 
-	result, err := r.pg.Pool.Exec(ctx, query, args...)
+	sub, err := scanSubscription(r.pg.Conn(ctx).QueryRow(ctx, query, args...))
 	if err != nil {
-		return fmt.Errorf("Ошибка при обновлении подписки: %w", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Subscription{}, r.missingOrConflict(ctx, id)
+		}
+		return domain.Subscription{}, fmt.Errorf("Ошибка при обновлении подписки: %w", checkViolation(err))
 	}
 
-	if result.RowsAffected() == 0 {
-		return domain.ErrSubscriptionNotFound
+	if input.StartDate != nil {
+		if err := r.moveFirstPeriod(ctx, tenantID, id, *input.StartDate); err != nil {
+			return domain.Subscription{}, err
+		}
 	}
 
-	return nil
+	return sub, nil
 }
 
-// Удаление подписки
-func (r *SubscriptionRepository) Delete(ctx context.Context, id string) error {
+// Перенос начала первого периода активности на новую дату начала подписки.
+// Периоды, закончившиеся до новой даты начала, удаляются: до начала подписки статусов нет
+func (r *SubscriptionRepository) moveFirstPeriod(ctx context.Context, tenantID, id string, startDate time.Time) error {
 	query := `
-	DELETE 
-	FROM subscriptions 
-	WHERE id = $1
+		WITH removed AS (
+			DELETE FROM subscription_periods
+			WHERE tenant_id = $1
+			AND subscription_id = $2
+			AND ended_at <= $3
+		)
+		UPDATE subscription_periods
+		SET started_at = $3
+		WHERE id = (
+			SELECT id FROM subscription_periods
+			WHERE tenant_id = $1
+			AND subscription_id = $2
+			AND (ended_at IS NULL OR ended_at > $3)
+			ORDER BY started_at, id
+			LIMIT 1
+		)
 	`
 
-	result, err := r.pg.Pool.Exec(ctx, query, id)
+	if _, err := r.pg.Conn(ctx).Exec(ctx, query, tenantID, id, startDate); err != nil {
+		return fmt.Errorf("Ошибка при переносе начала периода активности подписки: %w", err)
+	}
+
+	return nil
+}
+
+// Удаление подписки в корзину
+func (r *SubscriptionRepository) Delete(ctx context.Context, id string, ifVersion *int64) (domain.Subscription, error) {
+	tenantID, err := tenantFromContext(ctx)
 	if err != nil {
-		return fmt.Errorf("Ошибка при удалении подписки: %w", err)
+		return domain.Subscription{}, err
 	}
 
-	if result.RowsAffected() == 0 {
-		return domain.ErrSubscriptionNotFound
+	query := `
+		UPDATE subscriptions
+		SET deleted_at = now(), version = version + 1
+		WHERE tenant_id = $1
+		AND id = $2
+		AND deleted_at IS NULL
+		AND ($3::BIGINT IS NULL OR version = $3)
+		RETURNING ` + subscriptionColumns
+
+	sub, err := scanSubscription(r.pg.Conn(ctx).QueryRow(ctx, query, tenantID, id, ifVersion))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Subscription{}, r.missingOrConflict(ctx, id)
+		}
+		return domain.Subscription{}, fmt.Errorf("Ошибка при удалении подписки: %w", err)
 	}
 
-	return nil
+	return sub, nil
+}
+
+// Причина, по которой условное изменение не затронуло ни одной строки:
+// подписки нет или ее версия не совпала с ожидаемой
+func (r *SubscriptionRepository) missingOrConflict(ctx context.Context, id string) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+
+	query := "SELECT EXISTS(SELECT 1 FROM subscriptions WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL)"
+
+	if err := r.pg.Conn(ctx).QueryRow(ctx, query, tenantID, id).Scan(&exists); err != nil {
+		return fmt.Errorf("Ошибка при проверке подписки: %w", err)
+	}
+
+	if exists {
+		return domain.ErrVersionMismatch
+	}
+
+	return domain.ErrSubscriptionNotFound
 }
 
-// Получение списка подписок
-func (r *SubscriptionRepository) List(ctx context.Context, userID string) ([]domain.Subscription, error) {
+// Восстановление подписки из корзины
+func (r *SubscriptionRepository) Restore(ctx context.Context, id string) (domain.Subscription, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
 	query := `
-		SELECT service_name, price, user_id, start_date, end_date
+		UPDATE subscriptions
+		SET deleted_at = NULL, version = version + 1
+		WHERE tenant_id = $1
+		AND id = $2
+		AND deleted_at IS NOT NULL
+		RETURNING ` + subscriptionColumns
+
+	sub, err := scanSubscription(r.pg.Conn(ctx).QueryRow(ctx, query, tenantID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Subscription{}, domain.ErrSubscriptionNotFound
+		}
+		return domain.Subscription{}, fmt.Errorf("Ошибка при восстановлении подписки: %w", err)
+	}
+
+	return sub, nil
+}
+
+// Смена статуса подписки.
+//
+// Для статусов с периодом активности текущий период закрывается датой change.At
+// и с нее же открывается период в новом статусе
+func (r *SubscriptionRepository) ChangeStatus(ctx context.Context, id string, change domain.StatusChange) (domain.Subscription, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	query := `
+		UPDATE subscriptions
+		SET status = $3, end_date = COALESCE($4, end_date), version = version + 1
+		WHERE tenant_id = $1
+		AND id = $2
+		AND deleted_at IS NULL
+		AND ($5::BIGINT IS NULL OR version = $5)
+		RETURNING ` + subscriptionColumns
+
+	sub, err := scanSubscription(r.pg.Conn(ctx).QueryRow(ctx, query, tenantID, id, change.Status, change.EndDate, change.IfVersion))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Subscription{}, r.missingOrConflict(ctx, id)
+		}
+		return domain.Subscription{}, fmt.Errorf("Ошибка при смене статуса подписки: %w", checkViolation(err))
+	}
+
+	if !change.Status.HasPeriod() {
+		return sub, nil
+	}
+
+	periodQuery := `
+		WITH closed AS (
+			UPDATE subscription_periods
+			SET ended_at = $3
+			WHERE tenant_id = $1
+			AND subscription_id = $2
+			AND ended_at IS NULL
+		)
+		INSERT INTO subscription_periods (subscription_id, status, started_at, tenant_id)
+		VALUES ($2, $4, $3, $1)
+	`
+
+	if _, err := r.pg.Conn(ctx).Exec(ctx, periodQuery, tenantID, id, change.At, change.Status); err != nil {
+		return domain.Subscription{}, fmt.Errorf("Ошибка при сохранении периода активности подписки: %w", err)
+	}
+
+	return sub, nil
+}
+
+// Получение подписок, статус которых должен смениться по дате: пробных,
+// у которых пробный период или срок подписки закончился раньше дня today,
+// и отмененных, срок которых закончился раньше today. Подписки из корзины не учитываются
+func (r *SubscriptionRepository) ListStatusDue(ctx context.Context, today time.Time) ([]domain.Subscription, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + subscriptionColumns + `
 		FROM subscriptions
-		WHERE user_id = $1
+		WHERE tenant_id = $1
+		AND deleted_at IS NULL
+		AND (
+			(status = 'trial' AND (trial_end_date < $2::DATE OR end_date < $2::DATE))
+			OR (status = 'cancelled' AND end_date < $2::DATE)
+		)
+		ORDER BY id
 	`
 
-	rows, err := r.pg.Pool.Query(ctx, query, userID)
+	rows, err := r.pg.Conn(ctx).Query(ctx, query, tenantID, today)
 	if err != nil {
-		return nil, fmt.Errorf("Ошибка при получении списка подписок: %w", err)
+		return nil, fmt.Errorf("Ошибка при получении подписок для смены статуса: %w", err)
 	}
 	defer rows.Close()
 
 	subs := make([]domain.Subscription, 0)
 
 	for rows.Next() {
-		var sub domain.Subscription
-
-		if err := rows.Scan(
-			&sub.ServiceName,
-			&sub.Price,
-			&sub.UserID,
-			&sub.StartDate,
-			&sub.EndDate,
-		); err != nil {
-			return nil, fmt.Errorf("Ошибка при сканировании списка подписок: %w", err)
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("Ошибка при сканировании подписок для смены статуса: %w", err)
 		}
 		subs = append(subs, sub)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("Ошибка при сканировании списка подписок: %w", err)
+		return nil, fmt.Errorf("Ошибка при сканировании подписок для смены статуса: %w", err)
 	}
 
 	return subs, nil
 }
 
-// Получение суммы подписок
-func (r *SubscriptionRepository) GetTotalCost(ctx context.Context, userID, serviceName string, startDate, endDate time.Time) (int, error) {
+// Окончательное удаление подписок, находящихся в корзине с момента раньше before.
+// Возвращает удаленные подписки
+func (r *SubscriptionRepository) Purge(ctx context.Context, before time.Time) ([]domain.Subscription, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT COALESCE(SUM(price), 0)
-		FROM subscriptions
-		WHERE user_id = $1
-		AND ($2 = '' OR service_name = $2)
-		AND start_date <= $4
-		AND (end_date IS NULL OR end_date >= $3)
+		DELETE FROM subscriptions
+		WHERE tenant_id = $1
+		AND deleted_at IS NOT NULL
+		AND deleted_at < $2
+		RETURNING ` + subscriptionColumns
+
+	rows, err := r.pg.Conn(ctx).Query(ctx, query, tenantID, before)
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при очистке корзины подписок: %w", err)
+	}
+	defer rows.Close()
+
+	purged := make([]domain.Subscription, 0)
+
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("Ошибка при сканировании удаленных подписок: %w", err)
+		}
+		purged = append(purged, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Ошибка при очистке корзины подписок: %w", err)
+	}
+
+	return purged, nil
+}
+
+// Получение истории цен подписки в хронологическом порядке
+func (r *SubscriptionRepository) ListPrices(ctx context.Context, id string) ([]domain.SubscriptionPrice, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT price, effective_from
+		FROM subscription_prices
+		WHERE tenant_id = $1
+		AND subscription_id = $2
+		ORDER BY effective_from
 	`
 
-	var total int
-	err := r.pg.Pool.QueryRow(ctx, query, userID, serviceName, startDate, endDate).Scan(&total)
+	rows, err := r.pg.Conn(ctx).Query(ctx, query, tenantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при получении истории цен: %w", err)
+	}
+	defer rows.Close()
+
+	prices := make([]domain.SubscriptionPrice, 0)
+
+	for rows.Next() {
+		var price domain.SubscriptionPrice
+		if err := rows.Scan(&price.Price, &price.EffectiveFrom); err != nil {
+			return nil, fmt.Errorf("Ошибка при сканировании истории цен: %w", err)
+		}
+		prices = append(prices, price)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Ошибка при сканировании истории цен: %w", err)
+	}
+
+	return prices, nil
+}
+
+// Колонки, по которым разрешена сортировка списка подписок
+var subscriptionSortColumns = map[string]string{
+	domain.SortByStartDate:   "start_date",
+	domain.SortByPrice:       "price",
+	domain.SortByServiceName: "service_name",
+}
+
+// Получение страницы списка подписок.
+//
+// Используется keyset-пагинация по паре (колонка сортировки, id): курсор хранит
+// значения последней строки страницы, и следующая страница начинается строго после нее
+func (r *SubscriptionRepository) List(ctx context.Context, filter domain.ListSubscriptionsFilter) (domain.SubscriptionPage, error) {
+	sortColumn, ok := subscriptionSortColumns[filter.SortBy]
+	if !ok {
+		return domain.SubscriptionPage{}, domain.ErrInvalidSort
+	}
+
+	tenantID, err := tenantFromContext(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("Ошибка при подсчете суммы подписок: %w", err)
+		return domain.SubscriptionPage{}, err
+	}
+
+	query := "SELECT " + subscriptionColumns + " FROM subscriptions WHERE tenant_id = $1 AND user_id = $2"
+	args := []any{tenantID, filter.UserID}
+	argId := 3
+
+	if filter.Deleted {
+		query += " AND deleted_at IS NOT NULL"
+	} else {
+		query += " AND deleted_at IS NULL"
+	}
+
+	if filter.ServiceName != "" {
+		query += fmt.Sprintf(" AND service_name = $%d", argId)
+		args = append(args, filter.ServiceName)
+		argId++
+	}
+	if filter.ActiveFrom != nil && filter.ActiveTo != nil {
+		query += fmt.Sprintf(" AND start_date <= $%d AND (end_date IS NULL OR end_date >= $%d)", argId, argId+1)
+		args = append(args, *filter.ActiveTo, *filter.ActiveFrom)
+		argId += 2
+	}
+	if filter.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argId)
+		args = append(args, filter.Status)
+		argId++
+	}
+	if filter.PriceMin != nil {
+		query += fmt.Sprintf(" AND price >= $%d", argId)
+		args = append(args, *filter.PriceMin)
+		argId++
+	}
+	if filter.PriceMax != nil {
+		query += fmt.Sprintf(" AND price <= $%d", argId)
+		args = append(args, *filter.PriceMax)
+		argId++
+	}
+	if filter.HasEndDate != nil {
+		if *filter.HasEndDate {
+			query += " AND end_date IS NOT NULL"
+		} else {
+			query += " AND end_date IS NULL"
+		}
+	}
+
+	direction, comparison := "ASC", ">"
+	if filter.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if filter.Cursor != "" {
+		cur, err := decodeCursor(filter.Cursor, filter.SortBy, filter.SortDesc)
+		if err != nil {
+			return domain.SubscriptionPage{}, err
+		}
+
+		query += fmt.Sprintf(" AND (%s, id) %s ($%d, $%d)", sortColumn, comparison, argId, argId+1)
+		args = append(args, cur.value, cur.id)
+		argId += 2
 	}
 
-	return total, nil
+	// Запрашиваем на одну строку больше, чтобы понять, есть ли следующая страница
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d", sortColumn, direction, direction, argId)
+	args = append(args, filter.Limit+1)
+
+	rows, err := r.pg.Conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return domain.SubscriptionPage{}, fmt.Errorf("Ошибка при получении списка подписок: %w", err)
+	}
+	defer rows.Close()
+
+	subs := make([]domain.Subscription, 0, filter.Limit)
+
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return domain.SubscriptionPage{}, fmt.Errorf("Ошибка при сканировании списка подписок: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.SubscriptionPage{}, fmt.Errorf("Ошибка при сканировании списка подписок: %w", err)
+	}
+
+	page := domain.SubscriptionPage{Items: subs}
+
+	if len(subs) > filter.Limit {
+		page.Items = subs[:filter.Limit]
+		page.NextCursor = encodeCursor(page.Items[filter.Limit-1], filter.SortBy, filter.SortDesc)
+	}
+
+	return page, nil
+}
+
+// Списания по подпискам пользователя $1 организации $6, чьи расчетные периоды
+// пересекаются с периодом отчета с даты $3 по дату $4 включительно.
+//
+// Подписка оплачивается в начале каждого расчетного периода, начиная с start_date,
+// поэтому каждая подписка дает по одной строке на каждое списание до конца отчета.
+// Списаний после end_date подписки нет.
+//
+// Дата n-го списания - start_date + n * период, а не предыдущее списание + период:
+// так день месяца не сдвигается после короткого месяца (31 января, 28 февраля, 31 марта).
+// Число списаний сверху ограничено числом дней до конца отчета, деленным на самый короткий
+// вариант периода (min_days), лишние даты отсекает условие WHERE
+//
+// Цена списания берется из истории цен: последняя запись, действующая на дату списания.
+// Если списание раньше первой записи, используется первая запись.
+//
+// Сумма списания пересчитывается в валюту отчета $5 по курсам организации.
+// Если нужного курса нет, amount будет NULL
+const chargesQuery = `
+	SELECT
+		s.id,
+		s.service_name,
+		c.charged_at,
+		c.next_charged_at,
+		s.end_date,
+		CASE
+			WHEN s.currency = $5 THEN p.price::NUMERIC
+			ELSE p.price * dst.rate / src.rate
+		END AS amount
+	FROM subscriptions s
+	CROSS JOIN LATERAL (
+		SELECT
+			CASE s.billing_period
+				WHEN 'weekly' THEN interval '1 week'
+				WHEN 'quarterly' THEN interval '3 months'
+				WHEN 'yearly' THEN interval '1 year'
+				ELSE interval '1 month'
+			END AS step,
+			CASE s.billing_period
+				WHEN 'weekly' THEN 7
+				WHEN 'quarterly' THEN 89
+				WHEN 'yearly' THEN 365
+				ELSE 28
+			END AS min_days,
+			LEAST(COALESCE(s.end_date, $4::DATE), $4::DATE) AS last_day
+	) AS b
+	CROSS JOIN LATERAL generate_series(0, (b.last_day - s.start_date) / b.min_days) AS k(n)
+	CROSS JOIN LATERAL (
+		SELECT
+			s.start_date + b.step * k.n AS charged_at,
+			s.start_date + b.step * (k.n + 1) AS next_charged_at
+	) AS c
+	CROSS JOIN LATERAL (
+		SELECT COALESCE(
+			(
+				SELECT sp.price FROM subscription_prices sp
+				WHERE sp.subscription_id = s.id
+				AND sp.effective_from <= c.charged_at
+				ORDER BY sp.effective_from DESC
+				LIMIT 1
+			),
+			(
+				SELECT sp.price FROM subscription_prices sp
+				WHERE sp.subscription_id = s.id
+				ORDER BY sp.effective_from
+				LIMIT 1
+			),
+			s.price
+		) AS price
+	) AS p
+	LEFT JOIN exchange_rates src ON src.tenant_id = s.tenant_id AND src.currency = s.currency
+	LEFT JOIN exchange_rates dst ON dst.tenant_id = s.tenant_id AND dst.currency = $5
+	WHERE s.tenant_id = $6
+	AND s.user_id = $1
+	AND s.deleted_at IS NULL
+	AND ($2 = '' OR s.service_name = $2)
+	AND s.start_date <= $4::DATE
+	AND (s.end_date IS NULL OR s.end_date >= $3::DATE)
+	AND c.charged_at <= b.last_day
+	AND c.next_charged_at > $3::DATE
+	ORDER BY c.charged_at, s.service_name
+`
+
+// Неоплачиваемые периоды подписок $2 организации $1, пересекающиеся с периодом с $3 по $4.
+// Пробный период заканчивается не позже дня после trial_end_date, даже если
+// фоновая задача еще не перевела подписку в оплачиваемую
+const freePeriodsQuery = `
+	SELECT subscription_id, status, started_at, ended_at
+	FROM (
+		SELECT
+			p.subscription_id,
+			p.status,
+			p.started_at,
+			CASE
+				WHEN p.status = 'trial' THEN LEAST(p.ended_at, s.trial_end_date + 1)
+				ELSE p.ended_at
+			END AS ended_at
+		FROM subscription_periods p
+		JOIN subscriptions s ON s.id = p.subscription_id
+		WHERE p.tenant_id = $1
+		AND p.subscription_id = ANY($2)
+		AND p.status IN ('trial', 'paused')
+	) AS periods
+	WHERE started_at <= $4::DATE
+	AND (ended_at IS NULL OR ended_at > $3::DATE)
+	ORDER BY subscription_id, started_at
+`
+
+// Получение списаний по подпискам за период отчета вместе с неоплачиваемыми периодами подписок.
+// Как списания распределяются по месяцам отчета, решает сервис
+func (r *SubscriptionRepository) ListCharges(ctx context.Context, filter domain.CostReportFilter) ([]domain.Charge, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pg.Conn(ctx).Query(ctx, chargesQuery,
+		filter.UserID,
+		filter.ServiceName,
+		filter.StartDate,
+		filter.EndDate,
+		filter.Currency,
+		tenantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при получении списаний по подпискам: %w", err)
+	}
+	defer rows.Close()
+
+	charges := make([]domain.Charge, 0)
+
+	for rows.Next() {
+		var (
+			charge domain.Charge
+			amount *float64
+		)
+
+		if err := rows.Scan(&charge.SubscriptionID, &charge.ServiceName, &charge.PeriodStart, &charge.PeriodEnd, &charge.EndDate, &amount); err != nil {
+			return nil, fmt.Errorf("Ошибка при сканировании списаний по подпискам: %w", err)
+		}
+		if amount == nil {
+			return nil, domain.ErrExchangeRateNotFound
+		}
+		charge.Amount = *amount
+		charges = append(charges, charge)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Ошибка при сканировании списаний по подпискам: %w", err)
+	}
+
+	if err := r.attachFreePeriods(ctx, tenantID, charges); err != nil {
+		return nil, err
+	}
+
+	return charges, nil
+}
+
+// Заполнение неоплачиваемых периодов подписок у списаний.
+// Периоды берутся за все расчетные периоды списаний, а не только за период отчета:
+// без пропорционального расчета от них зависит сумма всего списания
+func (r *SubscriptionRepository) attachFreePeriods(ctx context.Context, tenantID string, charges []domain.Charge) error {
+	if len(charges) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0)
+	seen := make(map[string]bool)
+	from, to := charges[0].PeriodStart, charges[0].PeriodEnd
+	for _, charge := range charges {
+		if !seen[charge.SubscriptionID] {
+			seen[charge.SubscriptionID] = true
+			ids = append(ids, charge.SubscriptionID)
+		}
+		if charge.PeriodStart.Before(from) {
+			from = charge.PeriodStart
+		}
+		if charge.PeriodEnd.After(to) {
+			to = charge.PeriodEnd
+		}
+	}
+
+	rows, err := r.pg.Conn(ctx).Query(ctx, freePeriodsQuery, tenantID, ids, from, to)
+	if err != nil {
+		return fmt.Errorf("Ошибка при получении периодов активности подписок: %w", err)
+	}
+	defer rows.Close()
+
+	periods := make(map[string][]domain.StatusPeriod)
+
+	for rows.Next() {
+		var (
+			subscriptionID string
+			period         domain.StatusPeriod
+		)
+
+		if err := rows.Scan(&subscriptionID, &period.Status, &period.From, &period.To); err != nil {
+			return fmt.Errorf("Ошибка при сканировании периодов активности подписок: %w", err)
+		}
+		periods[subscriptionID] = append(periods[subscriptionID], period)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Ошибка при сканировании периодов активности подписок: %w", err)
+	}
+
+	for i := range charges {
+		charges[i].FreePeriods = periods[charges[i].SubscriptionID]
+	}
+
+	return nil
 }
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/levinOo/go-crudl-task/internal/db"
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Структура репозитория истории изменений подписок
+type SubscriptionEventRepository struct {
+	pg *db.Postgres
+}
+
+// Функция конструктор
+func NewSubscriptionEventRepository(pg *db.Postgres) *SubscriptionEventRepository {
+	return &SubscriptionEventRepository{pg: pg}
+}
+
+// Сохранение записи истории.
+// Вызывается в транзакции изменения, чтобы запись не разошлась с самим изменением
+func (r *SubscriptionEventRepository) Create(ctx context.Context, event domain.SubscriptionEvent) error {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO subscription_events (subscription_id, action, actor, before, after, tenant_id)
+		VALUES ($1, $2, NULLIF($3, ''), $4::JSONB, $5::JSONB, $6)
+	`
+
+	_, err = r.pg.Conn(ctx).Exec(ctx, query,
+		event.SubscriptionID,
+		event.Action,
+		event.Actor,
+		event.Before,
+		event.After,
+		tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("Ошибка при сохранении истории подписки: %w", err)
+	}
+
+	return nil
+}
+
+// Получение истории изменений подписки в хронологическом порядке
+func (r *SubscriptionEventRepository) List(ctx context.Context, subscriptionID string) ([]domain.SubscriptionEvent, error) {
+	tenantID, err := tenantFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, subscription_id, action, COALESCE(actor, ''), before, after, created_at
+		FROM subscription_events
+		WHERE tenant_id = $1
+		AND subscription_id = $2
+		ORDER BY id
+	`
+
+	rows, err := r.pg.Conn(ctx).Query(ctx, query, tenantID, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при получении истории подписки: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]domain.SubscriptionEvent, 0)
+
+	for rows.Next() {
+		var event domain.SubscriptionEvent
+
+		err := rows.Scan(
+			&event.ID,
+			&event.SubscriptionID,
+			&event.Action,
+			&event.Actor,
+			&event.Before,
+			&event.After,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Ошибка при сканировании истории подписки: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Ошибка при сканировании истории подписки: %w", err)
+	}
+
+	return events, nil
+}
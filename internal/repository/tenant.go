@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/levinOo/go-crudl-task/internal/db"
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Организация запроса из контекста.
+// Запросы без организации не выполняются, даже если политики RLS их бы отфильтровали
+func tenantFromContext(ctx context.Context) (string, error) {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return "", domain.ErrTenantRequired
+	}
+
+	return tenantID, nil
+}
+
+// Структура репозитория организаций
+type TenantRepository struct {
+	pg *db.Postgres
+}
+
+// Функция конструктор
+func NewTenantRepository(pg *db.Postgres) *TenantRepository {
+	return &TenantRepository{pg: pg}
+}
+
+// Получение всех организаций, у которых есть подписки.
+// Таблица tenants без RLS: фоновые задачи перебирают организации до того, как выбрана одна из них
+func (r *TenantRepository) List(ctx context.Context) ([]string, error) {
+	rows, err := r.pg.Conn(ctx).Query(ctx, `SELECT id FROM tenants ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("Ошибка при получении организаций: %w", err)
+	}
+	defer rows.Close()
+
+	tenants := make([]string, 0)
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("Ошибка при сканировании организаций: %w", err)
+		}
+		tenants = append(tenants, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Ошибка при сканировании организаций: %w", err)
+	}
+
+	return tenants, nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Интерфейс репозитория API ключей
+type APIKeyRepo interface {
+	Create(ctx context.Context, key domain.APIKey) (domain.APIKey, error)
+	Get(ctx context.Context, id string) (domain.APIKey, error)
+	List(ctx context.Context, userID string) ([]domain.APIKey, error)
+	Rotate(ctx context.Context, userID, id string, salt, secretHash []byte) (domain.APIKey, error)
+	Revoke(ctx context.Context, userID, id string) error
+	TouchLastUsed(ctx context.Context, id string) error
+}
+
+// Размеры соли и секрета API ключа в байтах
+const (
+	apiKeySaltSize   = 16
+	apiKeySecretSize = 32
+)
+
+// Формат ID API ключа
+var apiKeyIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// Структура сервиса API ключей
+type APIKeyServiceImplementation struct {
+	repo APIKeyRepo
+}
+
+// Функция конструктор сервиса API ключей
+func NewAPIKeyService(repo APIKeyRepository) *APIKeyServiceImplementation {
+	return &APIKeyServiceImplementation{
+		repo: repo,
+	}
+}
+
+// Функция создания API ключа от имени владельца owner
+func (s *APIKeyServiceImplementation) Create(ctx context.Context, owner domain.Principal, name string, scopes []domain.Scope) (domain.IssuedAPIKey, error) {
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return domain.IssuedAPIKey{}, domain.ErrInvalidScope
+		}
+	}
+
+	secret, salt, secretHash, err := newAPIKeySecret()
+	if err != nil {
+		return domain.IssuedAPIKey{}, err
+	}
+
+	key, err := s.repo.Create(ctx, domain.APIKey{
+		UserID:     owner.UserID,
+		TenantID:   owner.TenantID,
+		Name:       name,
+		Scopes:     scopes,
+		Salt:       salt,
+		SecretHash: secretHash,
+	})
+	if err != nil {
+		return domain.IssuedAPIKey{}, err
+	}
+
+	return issueAPIKey(key, secret), nil
+}
+
+// Функция получения API ключей пользователя
+func (s *APIKeyServiceImplementation) List(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	keys, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Функция смены секрета API ключа. Старый секрет сразу перестает действовать
+func (s *APIKeyServiceImplementation) Rotate(ctx context.Context, userID, id string) (domain.IssuedAPIKey, error) {
+	if !apiKeyIDPattern.MatchString(id) {
+		return domain.IssuedAPIKey{}, domain.ErrAPIKeyNotFound
+	}
+
+	secret, salt, secretHash, err := newAPIKeySecret()
+	if err != nil {
+		return domain.IssuedAPIKey{}, err
+	}
+
+	key, err := s.repo.Rotate(ctx, userID, id, salt, secretHash)
+	if err != nil {
+		return domain.IssuedAPIKey{}, err
+	}
+
+	return issueAPIKey(key, secret), nil
+}
+
+// Функция отзыва API ключа
+func (s *APIKeyServiceImplementation) Revoke(ctx context.Context, userID, id string) error {
+	if !apiKeyIDPattern.MatchString(id) {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	return s.repo.Revoke(ctx, userID, id)
+}
+
+// Функция проверки API ключа вида <id>.<secret> и получения пользователя по нему.
+// Роль владельца известна только из его токена, поэтому ключ всегда действует с правами роли user:
+// только со своими подписками и в пределах своих областей доступа
+func (s *APIKeyServiceImplementation) Authenticate(ctx context.Context, rawKey string) (domain.Principal, error) {
+	id, secret, ok := strings.Cut(rawKey, ".")
+	if !ok || !apiKeyIDPattern.MatchString(id) {
+		return domain.Principal{}, domain.ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return domain.Principal{}, domain.ErrInvalidAPIKey
+		}
+		return domain.Principal{}, err
+	}
+
+	if key.RevokedAt != nil {
+		return domain.Principal{}, domain.ErrInvalidAPIKey
+	}
+
+	if subtle.ConstantTimeCompare(hashAPIKeySecret(key.Salt, secret), key.SecretHash) != 1 {
+		return domain.Principal{}, domain.ErrInvalidAPIKey
+	}
+
+	if err := s.repo.TouchLastUsed(ctx, key.ID); err != nil {
+		return domain.Principal{}, err
+	}
+
+	return domain.Principal{
+		UserID:   key.UserID,
+		Role:     domain.RoleUser,
+		TenantID: key.TenantID,
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// Генерация нового секрета, соли и хеша секрета
+func newAPIKeySecret() (string, []byte, []byte, error) {
+	secretBytes := make([]byte, apiKeySecretSize)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", nil, nil, fmt.Errorf("Ошибка при генерации секрета API ключа: %w", err)
+	}
+
+	salt := make([]byte, apiKeySaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", nil, nil, fmt.Errorf("Ошибка при генерации соли API ключа: %w", err)
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	return secret, salt, hashAPIKeySecret(salt, secret), nil
+}
+
+// SHA-256 от соли и секрета
+func hashAPIKeySecret(salt []byte, secret string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(secret))
+
+	return h.Sum(nil)
+}
+
+// Ключ для выдачи клиенту
+func issueAPIKey(key domain.APIKey, secret string) domain.IssuedAPIKey {
+	return domain.IssuedAPIKey{
+		APIKey: key,
+		Key:    key.ID + "." + secret,
+	}
+}
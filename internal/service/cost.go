@@ -0,0 +1,160 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Стоимость списаний за период отчета с from по to включительно по месяцам и сервисам.
+//
+// Без пропорционального расчета списание целиком относится к месяцу своей даты,
+// если дата попадает в период отчета. Если дата списания приходится на пробный период
+// или паузу, списание переносится на первый оплачиваемый день после них
+// (для пробного периода - день после trial_end_date).
+//
+// При посуточном расчете цена расчетного периода делится поровну между его днями.
+// В отчет попадают дни периода внутри отчета и не позже даты окончания подписки,
+// поэтому неполные первый и последний месяцы оплачиваются пропорционально дням активности.
+//
+// Пробный период и пауза не оплачиваются: без пропорционального расчета списание уменьшается
+// на долю неоплачиваемых дней своего расчетного периода, при посуточном не оплачиваются сами эти дни.
+// Так однодневная пауза в день списания и пауза между списаниями стоят одинаково за каждый день.
+//
+// Результат отсортирован по месяцу и названию сервиса. Суммы не округляются
+func chargeCosts(charges []domain.Charge, from, to time.Time, proration domain.Proration) []domain.MonthlyServiceCost {
+	type key struct {
+		month   time.Time
+		service string
+	}
+
+	costs := make(map[key]float64)
+
+	for _, charge := range charges {
+		periodDays := daysBetween(charge.PeriodStart, charge.PeriodEnd)
+		if periodDays <= 0 {
+			continue
+		}
+
+		if proration != domain.ProrationDaily {
+			paid := paidDays(charge.FreePeriods, charge.PeriodStart, charge.PeriodEnd)
+			if paid <= 0 {
+				continue
+			}
+			chargedAt := firstPaidDay(charge.FreePeriods, charge.PeriodStart)
+			if chargedAt.Before(from) || chargedAt.After(to) {
+				continue
+			}
+			costs[key{monthOf(chargedAt), charge.ServiceName}] += charge.Amount * float64(paid) / float64(periodDays)
+			continue
+		}
+
+		// Активные дни периода внутри отчета: [start, end)
+		start := latest(charge.PeriodStart, from)
+		end := earliest(charge.PeriodEnd, to.AddDate(0, 0, 1))
+		if charge.EndDate != nil {
+			end = earliest(end, charge.EndDate.AddDate(0, 0, 1))
+		}
+
+		perDay := charge.Amount / float64(periodDays)
+
+		// Оплачиваемые дни могут приходиться на разные месяцы
+		for day := start; day.Before(end); {
+			month := monthOf(day)
+			next := earliest(month.AddDate(0, 1, 0), end)
+			costs[key{month, charge.ServiceName}] += perDay * float64(paidDays(charge.FreePeriods, day, next))
+			day = next
+		}
+	}
+
+	result := make([]domain.MonthlyServiceCost, 0, len(costs))
+	for k, cost := range costs {
+		result = append(result, domain.MonthlyServiceCost{Month: k.month, ServiceName: k.service, Cost: cost})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Month.Equal(result[j].Month) {
+			return result[i].Month.Before(result[j].Month)
+		}
+		return result[i].ServiceName < result[j].ServiceName
+	})
+
+	return result
+}
+
+// Число оплачиваемых дней с from по to, to не включается.
+// Неоплачиваемые периоды одной подписки не пересекаются
+func paidDays(periods []domain.StatusPeriod, from, to time.Time) int {
+	days := daysBetween(from, to)
+	for _, p := range periods {
+		start := latest(p.From, from)
+		end := to
+		if p.To != nil {
+			end = earliest(*p.To, to)
+		}
+		if start.Before(end) {
+			days -= daysBetween(start, end)
+		}
+	}
+	return days
+}
+
+// Первый оплачиваемый день, начиная с day: day или день окончания
+// неоплачиваемых периодов, в которые он попадает
+func firstPaidDay(periods []domain.StatusPeriod, day time.Time) time.Time {
+	for moved := true; moved; {
+		moved = false
+		for _, p := range periods {
+			if !day.Before(p.From) && p.To != nil && day.Before(*p.To) {
+				day = *p.To
+				moved = true
+			}
+		}
+	}
+	return day
+}
+
+// Итоговая стоимость отчета: сумма округленных стоимостей по месяцам и сервисам,
+// поэтому она совпадает с итогом разбивки стоимости
+func totalCost(costs []domain.MonthlyServiceCost) int {
+	total := 0
+	for _, cost := range costs {
+		total += roundCost(cost.Cost)
+	}
+	return total
+}
+
+// Округление стоимости сервиса за месяц до целых. Отчеты округляют только ее
+func roundCost(cost float64) int {
+	return int(math.Round(cost))
+}
+
+// Первый день месяца даты
+func monthOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Число дней между датами from и to, to не включается
+func daysBetween(from, to time.Time) int {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// Более ранняя из двух дат
+func earliest(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// Более поздняя из двух дат
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Дата без времени в UTC
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Указатель на дату
+func datePtr(year int, month time.Month, day int) *time.Time {
+	t := date(year, month, day)
+	return &t
+}
+
+func TestChargeCosts(t *testing.T) {
+	tests := []struct {
+		name      string
+		charges   []domain.Charge
+		from, to  time.Time
+		proration domain.Proration
+		want      []domain.MonthlyServiceCost
+	}{
+		{
+			name: "none: каждое списание целиком в месяце своей даты",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 15), PeriodEnd: date(2025, 2, 15), Amount: 300},
+				{ServiceName: "Netflix", PeriodStart: date(2025, 2, 15), PeriodEnd: date(2025, 3, 15), Amount: 300},
+				{ServiceName: "Netflix", PeriodStart: date(2025, 3, 15), PeriodEnd: date(2025, 4, 15), Amount: 300},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 3, 31),
+			proration: domain.ProrationNone,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 300},
+				{Month: date(2025, 2, 1), ServiceName: "Netflix", Cost: 300},
+				{Month: date(2025, 3, 1), ServiceName: "Netflix", Cost: 300},
+			},
+		},
+		{
+			name: "none: списание до начала отчета не учитывается",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2024, 12, 15), PeriodEnd: date(2025, 1, 15), Amount: 300},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 1, 31),
+			proration: domain.ProrationNone,
+			want:      []domain.MonthlyServiceCost{},
+		},
+		{
+			name: "none: сервисы одного месяца сортируются по названию",
+			charges: []domain.Charge{
+				{ServiceName: "Yandex Plus", PeriodStart: date(2025, 1, 1), PeriodEnd: date(2025, 2, 1), Amount: 400},
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 10), PeriodEnd: date(2025, 2, 10), Amount: 300},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 1, 31),
+			proration: domain.ProrationNone,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 300},
+				{Month: date(2025, 1, 1), ServiceName: "Yandex Plus", Cost: 400},
+			},
+		},
+		{
+			name: "daily: неполный первый месяц",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 15), PeriodEnd: date(2025, 2, 15), Amount: 310},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 1, 31),
+			proration: domain.ProrationDaily,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 170},
+			},
+		},
+		{
+			name: "daily: период переходит через границу месяца",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 15), PeriodEnd: date(2025, 2, 15), Amount: 310},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 2, 28),
+			proration: domain.ProrationDaily,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 170},
+				{Month: date(2025, 2, 1), ServiceName: "Netflix", Cost: 140},
+			},
+		},
+		{
+			name: "daily: неполный последний месяц отчета",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 1), PeriodEnd: date(2025, 2, 1), Amount: 310},
+			},
+			from:      date(2024, 12, 1),
+			to:        date(2025, 1, 20),
+			proration: domain.ProrationDaily,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 200},
+			},
+		},
+		{
+			name: "daily: дни после end_date не оплачиваются",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 1), PeriodEnd: date(2025, 2, 1), EndDate: datePtr(2025, 1, 10), Amount: 310},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 1, 31),
+			proration: domain.ProrationDaily,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 100},
+			},
+		},
+		{
+			name: "none: end_date не уменьшает оплаченное списание",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 1), PeriodEnd: date(2025, 2, 1), EndDate: datePtr(2025, 1, 10), Amount: 310},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 1, 31),
+			proration: domain.ProrationNone,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 310},
+			},
+		},
+		{
+			name: "none: пробный период в начале расчетного периода",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 1), PeriodEnd: date(2025, 2, 1), Amount: 310, FreePeriods: []domain.StatusPeriod{
+					{Status: domain.StatusTrial, From: date(2025, 1, 1), To: datePtr(2025, 1, 15)},
+				}},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 1, 31),
+			proration: domain.ProrationNone,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 170},
+			},
+		},
+		{
+			name: "daily: пробный период в начале расчетного периода",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 1), PeriodEnd: date(2025, 2, 1), Amount: 310, FreePeriods: []domain.StatusPeriod{
+					{Status: domain.StatusTrial, From: date(2025, 1, 1), To: datePtr(2025, 1, 15)},
+				}},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 1, 31),
+			proration: domain.ProrationDaily,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 170},
+			},
+		},
+		{
+			name: "none: списание после пробного периода переносится в следующий месяц",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 25), PeriodEnd: date(2025, 2, 25), Amount: 310, FreePeriods: []domain.StatusPeriod{
+					{Status: domain.StatusTrial, From: date(2025, 1, 25), To: datePtr(2025, 2, 11)},
+				}},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 2, 28),
+			proration: domain.ProrationNone,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 2, 1), ServiceName: "Netflix", Cost: 140},
+			},
+		},
+		{
+			name: "daily: пробный период через границу месяца",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 25), PeriodEnd: date(2025, 2, 25), Amount: 310, FreePeriods: []domain.StatusPeriod{
+					{Status: domain.StatusTrial, From: date(2025, 1, 25), To: datePtr(2025, 2, 11)},
+				}},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 2, 28),
+			proration: domain.ProrationDaily,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 0},
+				{Month: date(2025, 2, 1), ServiceName: "Netflix", Cost: 140},
+			},
+		},
+		{
+			name: "none: однодневная пауза в день годового списания",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 1), PeriodEnd: date(2026, 1, 1), Amount: 3650, FreePeriods: []domain.StatusPeriod{
+					{Status: domain.StatusPaused, From: date(2025, 1, 1), To: datePtr(2025, 1, 2)},
+				}},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 12, 31),
+			proration: domain.ProrationNone,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 3640},
+			},
+		},
+		{
+			name: "none: пауза между годовыми списаниями",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 1), PeriodEnd: date(2026, 1, 1), Amount: 3650, FreePeriods: []domain.StatusPeriod{
+					{Status: domain.StatusPaused, From: date(2025, 3, 1), To: datePtr(2025, 3, 30)},
+				}},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 12, 31),
+			proration: domain.ProrationNone,
+			want: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 3360},
+			},
+		},
+		{
+			name: "none: незавершенная пауза с даты списания",
+			charges: []domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 2, 1), PeriodEnd: date(2025, 3, 1), Amount: 280, FreePeriods: []domain.StatusPeriod{
+					{Status: domain.StatusPaused, From: date(2025, 2, 1)},
+				}},
+			},
+			from:      date(2025, 1, 1),
+			to:        date(2025, 3, 31),
+			proration: domain.ProrationNone,
+			want:      []domain.MonthlyServiceCost{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := chargeCosts(tt.charges, tt.from, tt.to, tt.proration)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("chargeCosts() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Month.Equal(tt.want[i].Month) || got[i].ServiceName != tt.want[i].ServiceName || math.Abs(got[i].Cost-tt.want[i].Cost) > 1e-9 {
+					t.Errorf("chargeCosts()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPaidDays(t *testing.T) {
+	tests := []struct {
+		name     string
+		periods  []domain.StatusPeriod
+		from, to time.Time
+		want     int
+	}{
+		{
+			name: "без неоплачиваемых периодов",
+			from: date(2025, 1, 1),
+			to:   date(2025, 2, 1),
+			want: 31,
+		},
+		{
+			name: "период частично до начала",
+			periods: []domain.StatusPeriod{
+				{Status: domain.StatusTrial, From: date(2024, 12, 20), To: datePtr(2025, 1, 5)},
+			},
+			from: date(2025, 1, 1),
+			to:   date(2025, 2, 1),
+			want: 27,
+		},
+		{
+			name: "незавершенный период",
+			periods: []domain.StatusPeriod{
+				{Status: domain.StatusPaused, From: date(2025, 1, 21)},
+			},
+			from: date(2025, 1, 1),
+			to:   date(2025, 2, 1),
+			want: 20,
+		},
+		{
+			name: "несколько периодов",
+			periods: []domain.StatusPeriod{
+				{Status: domain.StatusTrial, From: date(2025, 1, 1), To: datePtr(2025, 1, 8)},
+				{Status: domain.StatusPaused, From: date(2025, 1, 20), To: datePtr(2025, 1, 25)},
+			},
+			from: date(2025, 1, 1),
+			to:   date(2025, 2, 1),
+			want: 19,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paidDays(tt.periods, tt.from, tt.to); got != tt.want {
+				t.Errorf("paidDays() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildCostBreakdown(t *testing.T) {
+	costs := []domain.MonthlyServiceCost{
+		{Month: date(2025, 1, 1), ServiceName: "Yandex Plus", Cost: 100.4},
+		{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 300},
+		{Month: date(2025, 3, 1), ServiceName: "Yandex Plus", Cost: 100.4},
+		{Month: date(2025, 5, 1), ServiceName: "Netflix", Cost: 500},
+	}
+
+	got := buildCostBreakdown(costs, date(2025, 1, 15), date(2025, 3, 10))
+
+	wantMonths := []time.Time{date(2025, 1, 1), date(2025, 2, 1), date(2025, 3, 1)}
+	if len(got.Months) != len(wantMonths) {
+		t.Fatalf("Months = %v, want %v", got.Months, wantMonths)
+	}
+	for i := range wantMonths {
+		if !got.Months[i].Equal(wantMonths[i]) {
+			t.Errorf("Months[%d] = %v, want %v", i, got.Months[i], wantMonths[i])
+		}
+	}
+
+	wantServices := []domain.ServiceCostRow{
+		{ServiceName: "Netflix", Costs: []int{300, 0, 0}, Total: 300},
+		{ServiceName: "Yandex Plus", Costs: []int{100, 0, 100}, Total: 200},
+	}
+	if len(got.Services) != len(wantServices) {
+		t.Fatalf("Services = %v, want %v", got.Services, wantServices)
+	}
+	for i, want := range wantServices {
+		row := got.Services[i]
+		if row.ServiceName != want.ServiceName || row.Total != want.Total || !equalInts(row.Costs, want.Costs) {
+			t.Errorf("Services[%d] = %v, want %v", i, row, want)
+		}
+	}
+
+	if want := []int{400, 0, 100}; !equalInts(got.MonthTotals, want) {
+		t.Errorf("MonthTotals = %v, want %v", got.MonthTotals, want)
+	}
+	if got.Total != 500 {
+		t.Errorf("Total = %d, want 500", got.Total)
+	}
+}
+
+func TestTotalCostMatchesBreakdown(t *testing.T) {
+	from, to := date(2025, 1, 1), date(2025, 3, 31)
+
+	tests := []struct {
+		name  string
+		costs []domain.MonthlyServiceCost
+		want  int
+	}{
+		{
+			name: "дробные ячейки",
+			costs: []domain.MonthlyServiceCost{
+				{Month: date(2025, 1, 1), ServiceName: "Netflix", Cost: 100.4},
+				{Month: date(2025, 1, 1), ServiceName: "Yandex Plus", Cost: 100.4},
+				{Month: date(2025, 2, 1), ServiceName: "Netflix", Cost: 100.4},
+			},
+			want: 300,
+		},
+		{
+			name: "посуточный расчет через границы месяцев",
+			costs: chargeCosts([]domain.Charge{
+				{ServiceName: "Netflix", PeriodStart: date(2025, 1, 20), PeriodEnd: date(2025, 2, 20), Amount: 299},
+				{ServiceName: "Netflix", PeriodStart: date(2025, 2, 20), PeriodEnd: date(2025, 3, 20), Amount: 299},
+				{ServiceName: "Yandex Plus", PeriodStart: date(2025, 1, 7), PeriodEnd: date(2025, 4, 7), Amount: 599},
+			}, from, to, domain.ProrationDaily),
+			want: 1156,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total := totalCost(tt.costs)
+			breakdown := buildCostBreakdown(tt.costs, from, to)
+
+			if total != breakdown.Total {
+				t.Errorf("totalCost() = %d, buildCostBreakdown().Total = %d", total, breakdown.Total)
+			}
+			if total != tt.want {
+				t.Errorf("totalCost() = %d, want %d", total, tt.want)
+			}
+		})
+	}
+}
+
+// Срезы чисел совпадают поэлементно
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Базовая валюта курсов ЕЦБ
+const ecbBaseCurrency = "EUR"
+
+// Интерфейс репозитория курсов валют
+type ExchangeRateRepo interface {
+	ReplaceAll(ctx context.Context, rates []domain.ExchangeRate) error
+	List(ctx context.Context) ([]domain.ExchangeRate, error)
+}
+
+// Структура сервиса курсов валют
+type ExchangeRateServiceImplementation struct {
+	repo ExchangeRateRepo
+	tx   Transactor
+}
+
+// Функция конструктор сервиса курсов валют
+func NewExchangeRateService(repo ExchangeRateRepository, tx Transactor) *ExchangeRateServiceImplementation {
+	return &ExchangeRateServiceImplementation{
+		repo: repo,
+		tx:   tx,
+	}
+}
+
+// Функция загрузки курсов валют из файла.
+// Загруженные курсы полностью заменяют предыдущие
+func (s *ExchangeRateServiceImplementation) Load(ctx context.Context, format, base string, data io.Reader) ([]domain.ExchangeRate, error) {
+	var (
+		rates []domain.ExchangeRate
+		err   error
+	)
+
+	switch format {
+	case domain.RatesFormatECB:
+		rates, err = parseECBRates(data)
+	case domain.RatesFormatCSV:
+		rates, err = parseCSVRates(data)
+	default:
+		return nil, fmt.Errorf("%w: неизвестный формат %q", domain.ErrInvalidRatesFile, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if format == domain.RatesFormatECB {
+		base = ecbBaseCurrency
+	}
+	if base == "" {
+		return nil, fmt.Errorf("%w: не указана базовая валюта", domain.ErrInvalidRatesFile)
+	}
+	if !domain.ValidCurrency(base) {
+		return nil, domain.ErrInvalidCurrency
+	}
+
+	rates, err = withBaseCurrency(rates, base)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return s.repo.ReplaceAll(ctx, rates)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// Функция получения списка курсов валют
+func (s *ExchangeRateServiceImplementation) List(ctx context.Context) ([]domain.ExchangeRate, error) {
+	rates, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// Файл курсов ЕЦБ (eurofxref). Файл с историей содержит несколько дат,
+// берется самая поздняя
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// Разбор XML файла курсов в формате ЕЦБ
+func parseECBRates(data io.Reader) ([]domain.ExchangeRate, error) {
+	var envelope ecbEnvelope
+
+	if err := xml.NewDecoder(data).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidRatesFile, err)
+	}
+
+	latest := -1
+	for i, day := range envelope.Days {
+		// Даты в формате YYYY-MM-DD сравниваются как строки
+		if latest == -1 || day.Time > envelope.Days[latest].Time {
+			latest = i
+		}
+	}
+	if latest == -1 {
+		return nil, fmt.Errorf("%w: курсы не найдены", domain.ErrInvalidRatesFile)
+	}
+
+	rates := make([]domain.ExchangeRate, 0, len(envelope.Days[latest].Rates))
+	for _, item := range envelope.Days[latest].Rates {
+		rate, err := parseRate(item.Currency, item.Rate)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+// Разбор CSV файла курсов со строками вида currency,rate.
+// Строка заголовка необязательна
+func parseCSVRates(data io.Reader) ([]domain.ExchangeRate, error) {
+	reader := csv.NewReader(data)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	rates := make([]domain.ExchangeRate, 0)
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidRatesFile, err)
+		}
+
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "currency") {
+			continue
+		}
+
+		rate, err := parseRate(record[0], record[1])
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("%w: курсы не найдены", domain.ErrInvalidRatesFile)
+	}
+
+	return rates, nil
+}
+
+// Разбор и проверка одного курса
+func parseRate(currency, value string) (domain.ExchangeRate, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !domain.ValidCurrency(currency) {
+		return domain.ExchangeRate{}, fmt.Errorf("%w: неверный код валюты %q", domain.ErrInvalidRatesFile, currency)
+	}
+
+	rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || rate <= 0 {
+		return domain.ExchangeRate{}, fmt.Errorf("%w: неверный курс %q для %s", domain.ErrInvalidRatesFile, value, currency)
+	}
+
+	return domain.ExchangeRate{Currency: currency, Rate: rate}, nil
+}
+
+// Добавление базовой валюты с курсом 1 и проверка на дубликаты
+func withBaseCurrency(rates []domain.ExchangeRate, base string) ([]domain.ExchangeRate, error) {
+	seen := make(map[string]bool, len(rates)+1)
+	hasBase := false
+
+	for _, rate := range rates {
+		if seen[rate.Currency] {
+			return nil, fmt.Errorf("%w: валюта %s указана несколько раз", domain.ErrInvalidRatesFile, rate.Currency)
+		}
+		seen[rate.Currency] = true
+
+		if rate.Currency == base {
+			if rate.Rate != 1 {
+				return nil, fmt.Errorf("%w: курс базовой валюты %s должен быть равен 1", domain.ErrInvalidRatesFile, base)
+			}
+			hasBase = true
+		}
+	}
+
+	if !hasBase {
+		rates = append(rates, domain.ExchangeRate{Currency: base, Rate: 1})
+	}
+
+	return rates, nil
+}
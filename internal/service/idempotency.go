@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Интерфейс репозитория ключей идемпотентности
+type IdempotencyRepo interface {
+	Reserve(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error)
+	SaveResponse(ctx context.Context, userID, key string, statusCode int, response []byte) error
+	Release(ctx context.Context, userID, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// Структура сервиса ключей идемпотентности
+type IdempotencyServiceImplementation struct {
+	repo    IdempotencyRepo
+	tenants TenantRepository
+	tx      Transactor
+	ttl     time.Duration
+}
+
+// Функция конструктор сервиса ключей идемпотентности
+func NewIdempotencyService(repo IdempotencyRepository, tenants TenantRepository, tx Transactor, ttl time.Duration) *IdempotencyServiceImplementation {
+	return &IdempotencyServiceImplementation{
+		repo:    repo,
+		tenants: tenants,
+		tx:      tx,
+		ttl:     ttl,
+	}
+}
+
+// Функция начала запроса пользователя userID с ключом идемпотентности.
+// Ключи разных пользователей не пересекаются.
+//
+// Для нового ключа возвращает nil: запрос нужно выполнить через Complete.
+// Для повтора завершенного запроса возвращает сохраненный ответ
+func (s *IdempotencyServiceImplementation) Begin(ctx context.Context, userID, key, requestHash string) (*domain.IdempotencyRecord, error) {
+	record := domain.IdempotencyRecord{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(s.ttl),
+	}
+
+	existing, reserved, err := s.repo.Reserve(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, domain.ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == 0 {
+		return nil, domain.ErrIdempotencyInProgress
+	}
+
+	return &existing, nil
+}
+
+// Функция выполнения запроса с новым ключом и сохранения его ответа для повторов.
+//
+// fn возвращает код и тело ответа. fn и сохранение ответа выполняются в одной транзакции:
+// если что-то из них завершилось ошибкой, изменения fn откатываются, а ключ освобождается,
+// чтобы клиент мог повторить запрос
+func (s *IdempotencyServiceImplementation) Complete(ctx context.Context, userID, key string, fn func(ctx context.Context) (int, []byte, error)) error {
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		statusCode, response, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+
+		return s.repo.SaveResponse(ctx, userID, key, statusCode, response)
+	})
+	if err != nil {
+		if releaseErr := s.repo.Release(ctx, userID, key); releaseErr != nil {
+			return errors.Join(err, releaseErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Функция удаления истекших ключей во всех организациях.
+// Возвращает число удаленных ключей
+func (s *IdempotencyServiceImplementation) PurgeExpired(ctx context.Context) (int64, error) {
+	tenants, err := s.tenants.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		count int64
+		errs  []error
+	)
+
+	for _, tenantID := range tenants {
+		n, err := s.repo.DeleteExpired(domain.WithTenant(ctx, tenantID))
+		count += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Ошибка при удалении ключей идемпотентности организации %s: %w", tenantID, err))
+		}
+	}
+
+	return count, errors.Join(errs...)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Переход статуса подписки: из каких статусов допустим и в какой ведет
+type statusTransition struct {
+	from []domain.SubscriptionStatus
+	to   domain.SubscriptionStatus
+}
+
+// Допустимые переходы статусов подписки по действиям.
+// Отмененную и завершенную подписку нельзя приостановить или возобновить
+var statusTransitions = map[domain.SubscriptionAction]statusTransition{
+	domain.ActionActivated: {
+		from: []domain.SubscriptionStatus{domain.StatusTrial},
+		to:   domain.StatusActive,
+	},
+	domain.ActionPaused: {
+		from: []domain.SubscriptionStatus{domain.StatusActive},
+		to:   domain.StatusPaused,
+	},
+	domain.ActionResumed: {
+		from: []domain.SubscriptionStatus{domain.StatusPaused},
+		to:   domain.StatusActive,
+	},
+	domain.ActionCancelled: {
+		from: []domain.SubscriptionStatus{domain.StatusTrial, domain.StatusActive, domain.StatusPaused},
+		to:   domain.StatusCancelled,
+	},
+}
+
+// Функция смены статуса подписки действием action.
+//
+// Статус меняется с текущего дня в часовом поясе запроса, а для еще не начавшейся подписки - с даты начала.
+// Отмена оплачиваемой подписки действует до конца текущего расчетного периода,
+// пробной - до конца пробного периода, если он задан, приостановленной - с текущего дня.
+// Отмененную подписку после даты окончания завершает фоновая задача (см. CompleteTrials)
+func (s *SubscriptionServiceImplementation) ChangeStatus(ctx context.Context, id string, action domain.SubscriptionAction, ifVersion *int64) (domain.Subscription, error) {
+	transition, ok := statusTransitions[action]
+	if !ok {
+		return domain.Subscription{}, domain.ErrInvalidTransition
+	}
+
+	var updated domain.Subscription
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.repo.Lock(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current.DeletedAt != nil {
+			return domain.ErrSubscriptionNotFound
+		}
+		if ifVersion != nil && current.Version != *ifVersion {
+			return domain.ErrVersionMismatch
+		}
+		if !slices.Contains(transition.from, current.Status) {
+			return domain.ErrInvalidTransition
+		}
+
+		at := latest(today(ctx), current.StartDate)
+
+		// Закончившуюся подписку приостановить или отменить уже нельзя
+		if current.EndDate != nil && current.EndDate.Before(at) {
+			return domain.ErrInvalidTransition
+		}
+
+		change := domain.StatusChange{
+			Status:    transition.to,
+			At:        at,
+			IfVersion: ifVersion,
+		}
+
+		if transition.to == domain.StatusCancelled {
+			endDate := at
+			switch {
+			case current.Status == domain.StatusActive:
+				endDate = billingPeriodEnd(current, at)
+			case current.Status == domain.StatusTrial && current.TrialEndDate != nil:
+				endDate = latest(at, *current.TrialEndDate)
+			}
+			if current.EndDate != nil && current.EndDate.Before(endDate) {
+				endDate = *current.EndDate
+			}
+			change.EndDate = &endDate
+		}
+
+		updated, err = s.repo.ChangeStatus(ctx, id, change)
+		if err != nil {
+			return err
+		}
+
+		return s.record(ctx, action, id, &current, &updated)
+	})
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return updated, nil
+}
+
+// Последний день расчетного периода подписки, в который попадает дата at
+func billingPeriodEnd(sub domain.Subscription, at time.Time) time.Time {
+	n := 1
+	for !sub.BillingPeriod.Charge(sub.StartDate, n).After(at) {
+		n++
+	}
+	return sub.BillingPeriod.Charge(sub.StartDate, n).AddDate(0, 0, -1)
+}
+
+// Текущий день в часовом поясе запроса
+func today(ctx context.Context) time.Time {
+	now := time.Now().In(domain.LocationFromContext(ctx))
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+func TestBillingPeriodEnd(t *testing.T) {
+	tests := []struct {
+		name   string
+		period domain.BillingPeriod
+		start  time.Time
+		at     time.Time
+		want   time.Time
+	}{
+		{
+			name:   "weekly",
+			period: domain.BillingWeekly,
+			start:  date(2025, 1, 1),
+			at:     date(2025, 1, 10),
+			want:   date(2025, 1, 14),
+		},
+		{
+			name:   "monthly: день начала периода",
+			period: domain.BillingMonthly,
+			start:  date(2025, 1, 15),
+			at:     date(2025, 2, 15),
+			want:   date(2025, 3, 14),
+		},
+		{
+			name:   "monthly: 31 число не сдвигается после февраля",
+			period: domain.BillingMonthly,
+			start:  date(2025, 1, 31),
+			at:     date(2025, 4, 30),
+			want:   date(2025, 5, 30),
+		},
+		{
+			name:   "monthly: конец периода с 31 марта",
+			period: domain.BillingMonthly,
+			start:  date(2025, 1, 31),
+			at:     date(2025, 4, 29),
+			want:   date(2025, 4, 29),
+		},
+		{
+			name:   "quarterly",
+			period: domain.BillingQuarterly,
+			start:  date(2024, 11, 30),
+			at:     date(2025, 3, 1),
+			want:   date(2025, 5, 29),
+		},
+		{
+			name:   "yearly с 29 февраля",
+			period: domain.BillingYearly,
+			start:  date(2024, 2, 29),
+			at:     date(2028, 2, 28),
+			want:   date(2028, 2, 28),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := domain.Subscription{BillingPeriod: tt.period, StartDate: tt.start}
+			if got := billingPeriodEnd(sub, tt.at); !got.Equal(tt.want) {
+				t.Errorf("billingPeriodEnd() = %v, want %v", got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/levinOo/go-crudl-task/internal/domain"
@@ -10,37 +11,129 @@ import (
 
 // Интерфейс репозитория подписок
 type SubscriptionRepository interface {
-	Create(ctx context.Context, sub domain.Subscription) (string, error)
+	Create(ctx context.Context, sub domain.Subscription) (domain.Subscription, error)
 	Get(ctx context.Context, id string) (domain.Subscription, error)
-	Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) error
-	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, userID string) ([]domain.Subscription, error)
-	GetTotalCost(ctx context.Context, userID, serviceName string, startDate, endDate time.Time) (int, error)
+	Owner(ctx context.Context, id string) (string, error)
+	Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) (domain.Subscription, error)
+	Lock(ctx context.Context, id string) (domain.Subscription, error)
+	Delete(ctx context.Context, id string, ifVersion *int64) (domain.Subscription, error)
+	List(ctx context.Context, filter domain.ListSubscriptionsFilter) (domain.SubscriptionPage, error)
+	Restore(ctx context.Context, id string) (domain.Subscription, error)
+	ChangeStatus(ctx context.Context, id string, change domain.StatusChange) (domain.Subscription, error)
+	ListStatusDue(ctx context.Context, today time.Time) ([]domain.Subscription, error)
+	Purge(ctx context.Context, before time.Time) ([]domain.Subscription, error)
+	ListPrices(ctx context.Context, id string) ([]domain.SubscriptionPrice, error)
+	ListCharges(ctx context.Context, filter domain.CostReportFilter) ([]domain.Charge, error)
+}
+
+// Интерфейс репозитория истории изменений подписок
+type SubscriptionEventRepository interface {
+	Create(ctx context.Context, event domain.SubscriptionEvent) error
+	List(ctx context.Context, subscriptionID string) ([]domain.SubscriptionEvent, error)
+}
+
+// Интерфейс репозитория организаций
+type TenantRepository interface {
+	List(ctx context.Context) ([]string, error)
+}
+
+// Интерфейс публикации событий подписок для обработчиков вне сервиса (уведомлений и т.п.)
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.SubscriptionEvent) error
+}
+
+// Интерфейс выполнения операций в одной транзакции
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 // Интерфейс сервиса подписок
 type SubscriptionService interface {
-	Create(ctx context.Context, sub domain.Subscription) (string, error)
+	Create(ctx context.Context, sub domain.Subscription) (domain.Subscription, error)
 	Get(ctx context.Context, id string) (domain.Subscription, error)
-	Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) error
-	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, userID string) ([]domain.Subscription, error)
-	GetTotalCost(ctx context.Context, userID, serviceName string, startDate, endDate time.Time) (int, error)
+	Owner(ctx context.Context, id string) (string, error)
+	Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) (domain.Subscription, error)
+	Replace(ctx context.Context, id string, sub domain.Subscription, ifVersion *int64) (domain.Subscription, error)
+	Delete(ctx context.Context, id string, ifVersion *int64) error
+	List(ctx context.Context, filter domain.ListSubscriptionsFilter) (domain.SubscriptionPage, error)
+	Restore(ctx context.Context, id string) (domain.Subscription, error)
+	ChangeStatus(ctx context.Context, id string, action domain.SubscriptionAction, ifVersion *int64) (domain.Subscription, error)
+	Purge(ctx context.Context) (int64, error)
+	CompleteTrials(ctx context.Context) (int, error)
+	History(ctx context.Context, id string) ([]domain.SubscriptionEvent, error)
+	Prices(ctx context.Context, id string) ([]domain.SubscriptionPrice, error)
+	GetTotalCost(ctx context.Context, filter domain.CostReportFilter) (int, error)
+	GetCostBreakdown(ctx context.Context, filter domain.CostReportFilter) (domain.CostBreakdown, error)
+}
+
+// Интерфейс репозитория курсов валют
+type ExchangeRateRepository interface {
+	ReplaceAll(ctx context.Context, rates []domain.ExchangeRate) error
+	List(ctx context.Context) ([]domain.ExchangeRate, error)
+}
+
+// Интерфейс сервиса курсов валют
+type ExchangeRateService interface {
+	Load(ctx context.Context, format, base string, data io.Reader) ([]domain.ExchangeRate, error)
+	List(ctx context.Context) ([]domain.ExchangeRate, error)
+}
+
+// Интерфейс репозитория ключей идемпотентности
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error)
+	SaveResponse(ctx context.Context, userID, key string, statusCode int, response []byte) error
+	Release(ctx context.Context, userID, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// Интерфейс сервиса ключей идемпотентности
+type IdempotencyService interface {
+	Begin(ctx context.Context, userID, key, requestHash string) (*domain.IdempotencyRecord, error)
+	Complete(ctx context.Context, userID, key string, fn func(ctx context.Context) (int, []byte, error)) error
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// Интерфейс репозитория API ключей
+type APIKeyRepository interface {
+	Create(ctx context.Context, key domain.APIKey) (domain.APIKey, error)
+	Get(ctx context.Context, id string) (domain.APIKey, error)
+	List(ctx context.Context, userID string) ([]domain.APIKey, error)
+	Rotate(ctx context.Context, userID, id string, salt, secretHash []byte) (domain.APIKey, error)
+	Revoke(ctx context.Context, userID, id string) error
+	TouchLastUsed(ctx context.Context, id string) error
+}
+
+// Интерфейс сервиса API ключей
+type APIKeyService interface {
+	Create(ctx context.Context, owner domain.Principal, name string, scopes []domain.Scope) (domain.IssuedAPIKey, error)
+	List(ctx context.Context, userID string) ([]domain.APIKey, error)
+	Rotate(ctx context.Context, userID, id string) (domain.IssuedAPIKey, error)
+	Revoke(ctx context.Context, userID, id string) error
+	Authenticate(ctx context.Context, rawKey string) (domain.Principal, error)
 }
 
 // Структура сервисов
 type Services struct {
 	Subscription SubscriptionService
+	ExchangeRate ExchangeRateService
+	Idempotency  IdempotencyService
+	APIKey       APIKeyService
 }
 
 // Структура зависимостей
 type Deps struct {
-	Repos repository.Repositories
+	Repos          repository.Repositories
+	IdempotencyTTL time.Duration
+	TrashRetention time.Duration
+	Publisher      EventPublisher
 }
 
 // Функция конструктор сервисов
 func NewServices(deps Deps) *Services {
 	return &Services{
-		Subscription: NewSubscriptionService(deps.Repos.Subscription),
+		Subscription: NewSubscriptionService(deps.Repos.Subscription, deps.Repos.SubscriptionEvent, deps.Repos.Tenant, deps.Repos.Transactor, deps.Publisher, deps.TrashRetention),
+		ExchangeRate: NewExchangeRateService(deps.Repos.ExchangeRate, deps.Repos.Transactor),
+		Idempotency:  NewIdempotencyService(deps.Repos.Idempotency, deps.Repos.Tenant, deps.Repos.Transactor, deps.IdempotencyTTL),
+		APIKey:       NewAPIKeyService(deps.Repos.APIKey),
 	}
 }
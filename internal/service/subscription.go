@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/levinOo/go-crudl-task/internal/domain"
@@ -9,34 +10,125 @@ import (
 
 // Интерфейс репозитория подписок
 type SubscriptionRepo interface {
-	Create(ctx context.Context, sub domain.Subscription) (string, error)
+	Create(ctx context.Context, sub domain.Subscription) (domain.Subscription, error)
 	Get(ctx context.Context, id string) (domain.Subscription, error)
-	Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) error
-	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, userID string) ([]domain.Subscription, error)
-	GetTotalCost(ctx context.Context, userID, serviceName string, startDate, endDate time.Time) (int, error)
+	Owner(ctx context.Context, id string) (string, error)
+	Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) (domain.Subscription, error)
+	Lock(ctx context.Context, id string) (domain.Subscription, error)
+	Delete(ctx context.Context, id string, ifVersion *int64) (domain.Subscription, error)
+	List(ctx context.Context, filter domain.ListSubscriptionsFilter) (domain.SubscriptionPage, error)
+	Restore(ctx context.Context, id string) (domain.Subscription, error)
+	ChangeStatus(ctx context.Context, id string, change domain.StatusChange) (domain.Subscription, error)
+	ListStatusDue(ctx context.Context, today time.Time) ([]domain.Subscription, error)
+	Purge(ctx context.Context, before time.Time) ([]domain.Subscription, error)
+	ListPrices(ctx context.Context, id string) ([]domain.SubscriptionPrice, error)
+	ListCharges(ctx context.Context, filter domain.CostReportFilter) ([]domain.Charge, error)
+}
+
+// Интерфейс репозитория истории изменений подписок
+type SubscriptionEventRepo interface {
+	Create(ctx context.Context, event domain.SubscriptionEvent) error
+	List(ctx context.Context, subscriptionID string) ([]domain.SubscriptionEvent, error)
 }
 
 // Структура сервиса подписок
 type SubscriptionServiceImplementation struct {
-	repo SubscriptionRepo
+	repo           SubscriptionRepo
+	events         SubscriptionEventRepo
+	tenants        TenantRepository
+	tx             Transactor
+	publisher      EventPublisher
+	trashRetention time.Duration
 }
 
 // Функция конструктор сервиса подписок
-func NewSubscriptionService(repo SubscriptionRepository) *SubscriptionServiceImplementation {
+func NewSubscriptionService(repo SubscriptionRepository, events SubscriptionEventRepository, tenants TenantRepository, tx Transactor, publisher EventPublisher, trashRetention time.Duration) *SubscriptionServiceImplementation {
 	return &SubscriptionServiceImplementation{
-		repo: repo,
+		repo:           repo,
+		events:         events,
+		tenants:        tenants,
+		tx:             tx,
+		publisher:      publisher,
+		trashRetention: trashRetention,
 	}
 }
 
 // Функция создания подписки
-func (s *SubscriptionServiceImplementation) Create(ctx context.Context, sub domain.Subscription) (string, error) {
-	id, err := s.repo.Create(ctx, sub)
+func (s *SubscriptionServiceImplementation) Create(ctx context.Context, sub domain.Subscription) (domain.Subscription, error) {
+	sub, err := prepareSubscription(sub)
 	if err != nil {
-		return "", err
+		return domain.Subscription{}, err
+	}
+
+	var created domain.Subscription
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		created, err = s.repo.Create(ctx, sub)
+		if err != nil {
+			return err
+		}
+
+		return s.record(ctx, domain.ActionCreated, created.ID, nil, &created)
+	})
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	return created, nil
+}
+
+// Запись изменения подписки в историю.
+// Инициатор изменения берется из контекста запроса
+func (s *SubscriptionServiceImplementation) record(ctx context.Context, action domain.SubscriptionAction, id string, before, after *domain.Subscription) error {
+	return s.events.Create(ctx, newEvent(ctx, action, id, before, after))
+}
+
+// Запись истории изменения подписки, инициатор берется из контекста
+func newEvent(ctx context.Context, action domain.SubscriptionAction, id string, before, after *domain.Subscription) domain.SubscriptionEvent {
+	return domain.SubscriptionEvent{
+		SubscriptionID: id,
+		Action:         action,
+		Actor:          domain.ActorFromContext(ctx),
+		Before:         before,
+		After:          after,
+	}
+}
+
+// Заполнение значений по умолчанию и проверка полей подписки
+func prepareSubscription(sub domain.Subscription) (domain.Subscription, error) {
+	if sub.BillingPeriod == "" {
+		sub.BillingPeriod = domain.BillingMonthly
+	}
+	if !sub.BillingPeriod.Valid() {
+		return domain.Subscription{}, domain.ErrInvalidBillingPeriod
+	}
+	if sub.Currency == "" {
+		sub.Currency = domain.DefaultCurrency
+	}
+	if !domain.ValidCurrency(sub.Currency) {
+		return domain.Subscription{}, domain.ErrInvalidCurrency
+	}
+	// Подписка с датой окончания пробного периода по умолчанию начинается с него
+	if sub.Status == "" && sub.TrialEndDate != nil {
+		sub.Status = domain.StatusTrial
+	}
+	if sub.Status == "" {
+		sub.Status = domain.StatusActive
+	}
+	if sub.Status != domain.StatusActive && sub.Status != domain.StatusTrial {
+		return domain.Subscription{}, domain.ErrInvalidStatus
+	}
+	if sub.TrialEndDate != nil && sub.Status != domain.StatusTrial {
+		return domain.Subscription{}, domain.ErrInvalidStatus
+	}
+	if sub.EndDate != nil && sub.EndDate.Before(sub.StartDate) {
+		return domain.Subscription{}, domain.ErrInvalidPeriod
+	}
+	if sub.TrialEndDate != nil && sub.TrialEndDate.Before(sub.StartDate) {
+		return domain.Subscription{}, domain.ErrInvalidPeriod
 	}
 
-	return id, nil
+	return sub, nil
 }
 
 // Функция получения подписки
@@ -49,48 +141,345 @@ func (s *SubscriptionServiceImplementation) Get(ctx context.Context, id string)
 	return sub, nil
 }
 
+// Функция получения владельца подписки, в том числе удаленной
+func (s *SubscriptionServiceImplementation) Owner(ctx context.Context, id string) (string, error) {
+	owner, err := s.repo.Owner(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	return owner, nil
+}
+
 // Функция обновления подписки
-func (s *SubscriptionServiceImplementation) Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) error {
-	if input.EndDate != nil {
-		currentSub, err := s.repo.Get(ctx, id)
+func (s *SubscriptionServiceImplementation) Update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) (domain.Subscription, error) {
+	if input.BillingPeriod != nil && !input.BillingPeriod.Valid() {
+		return domain.Subscription{}, domain.ErrInvalidBillingPeriod
+	}
+	if input.Currency != nil && !domain.ValidCurrency(*input.Currency) {
+		return domain.Subscription{}, domain.ErrInvalidCurrency
+	}
+	if input.EffectiveFrom != nil && input.Price == nil {
+		return domain.Subscription{}, domain.ErrEffectiveFromNoPrice
+	}
+
+	return s.update(ctx, id, input)
+}
+
+// Изменение подписки с записью в историю
+func (s *SubscriptionServiceImplementation) update(ctx context.Context, id string, input domain.UpdateSubscriptionInput) (domain.Subscription, error) {
+	var updated domain.Subscription
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		currentSub, err := s.repo.Lock(ctx, id)
 		if err != nil {
 			return err
 		}
+		if currentSub.DeletedAt != nil {
+			return domain.ErrSubscriptionNotFound
+		}
+
+		// Даты проверяем с учетом текущих значений подписки
+		startDate := currentSub.StartDate
+		if input.StartDate != nil {
+			startDate = *input.StartDate
+		}
 
-		if input.EndDate.Before(currentSub.StartDate) {
+		endDate := currentSub.EndDate
+		if input.ClearEndDate {
+			endDate = nil
+		}
+		if input.EndDate != nil {
+			endDate = input.EndDate
+		}
+
+		if endDate != nil && endDate.Before(startDate) {
 			return domain.ErrInvalidPeriod
 		}
+
+		// Та же цена без даты начала действия (при PUT или PATCH) не создает запись в истории цен.
+		// Новая цена по умолчанию действует с текущей даты: списания, уже сделанные в этом месяце, не меняются
+		if input.Price != nil && input.EffectiveFrom == nil && *input.Price == int64(currentSub.Price) {
+			input.Price = nil
+		}
+		if input.Price != nil && input.EffectiveFrom == nil {
+			effectiveFrom := today(ctx)
+			input.EffectiveFrom = &effectiveFrom
+		}
+
+		updated, err = s.repo.Update(ctx, id, input)
+		if err != nil {
+			return err
+		}
+
+		// Версия не изменилась - изменять было нечего
+		if updated.Version == currentSub.Version {
+			return nil
+		}
+
+		return s.record(ctx, domain.ActionUpdated, id, &currentSub, &updated)
+	})
+	if err != nil {
+		return domain.Subscription{}, err
 	}
 
-	return s.repo.Update(ctx, id, input)
+	return updated, nil
+}
+
+// Функция полной замены подписки.
+// Заменяются все поля, кроме владельца; незаданные поля получают значения по умолчанию
+func (s *SubscriptionServiceImplementation) Replace(ctx context.Context, id string, sub domain.Subscription, ifVersion *int64) (domain.Subscription, error) {
+	sub, err := prepareSubscription(sub)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+
+	price := int64(sub.Price)
+
+	input := domain.UpdateSubscriptionInput{
+		ServiceName:   &sub.ServiceName,
+		Price:         &price,
+		Currency:      &sub.Currency,
+		BillingPeriod: &sub.BillingPeriod,
+		StartDate:     &sub.StartDate,
+		EndDate:       sub.EndDate,
+		ClearEndDate:  sub.EndDate == nil,
+		IfVersion:     ifVersion,
+	}
+
+	return s.update(ctx, id, input)
 }
 
 // Функция удаления подписки
-func (s *SubscriptionServiceImplementation) Delete(ctx context.Context, id string) error {
-	err := s.repo.Delete(ctx, id)
+func (s *SubscriptionServiceImplementation) Delete(ctx context.Context, id string, ifVersion *int64) error {
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.repo.Lock(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt != nil {
+			return domain.ErrSubscriptionNotFound
+		}
+
+		deleted, err := s.repo.Delete(ctx, id, ifVersion)
+		if err != nil {
+			return err
+		}
+
+		return s.record(ctx, domain.ActionDeleted, id, &before, &deleted)
+	})
+}
+
+// Функция восстановления подписки из корзины
+func (s *SubscriptionServiceImplementation) Restore(ctx context.Context, id string) (domain.Subscription, error) {
+	var restored domain.Subscription
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.repo.Lock(ctx, id)
+		if err != nil {
+			return err
+		}
+		if before.DeletedAt == nil {
+			return domain.ErrSubscriptionNotFound
+		}
+
+		restored, err = s.repo.Restore(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return s.record(ctx, domain.ActionRestored, id, &before, &restored)
+	})
 	if err != nil {
-		return err
+		return domain.Subscription{}, err
 	}
 
-	return nil
+	return restored, nil
 }
 
-// Функция получения списка подписок
-func (s *SubscriptionServiceImplementation) List(ctx context.Context, userID string) ([]domain.Subscription, error) {
-	list, err := s.repo.List(ctx, userID)
+// Функция очистки корзины: окончательно удаляет подписки,
+// пролежавшие в корзине дольше срока хранения
+func (s *SubscriptionServiceImplementation) Purge(ctx context.Context) (int64, error) {
+	var count int64
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		purged, err := s.repo.Purge(ctx, time.Now().Add(-s.trashRetention))
+		if err != nil {
+			return err
+		}
+
+		for i := range purged {
+			if err := s.record(ctx, domain.ActionPurged, purged[i].ID, &purged[i], nil); err != nil {
+				return err
+			}
+		}
+
+		count = int64(len(purged))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Функция получения истории цен подписки
+func (s *SubscriptionServiceImplementation) Prices(ctx context.Context, id string) ([]domain.SubscriptionPrice, error) {
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	prices, err := s.repo.ListPrices(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return list, nil
+	return prices, nil
+}
+
+// Функция получения истории изменений подписки.
+// История доступна и для подписок в корзине, и для окончательно удаленных
+func (s *SubscriptionServiceImplementation) History(ctx context.Context, id string) ([]domain.SubscriptionEvent, error) {
+	events, err := s.events.List(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, domain.ErrSubscriptionNotFound
+	}
+
+	return events, nil
+}
+
+// Размер страницы списка подписок
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// Функция получения списка подписок
+func (s *SubscriptionServiceImplementation) List(ctx context.Context, filter domain.ListSubscriptionsFilter) (domain.SubscriptionPage, error) {
+	if filter.SortBy == "" {
+		filter.SortBy = domain.SortByStartDate
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxListLimit {
+		return domain.SubscriptionPage{}, domain.ErrInvalidLimit
+	}
+
+	page, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return domain.SubscriptionPage{}, err
+	}
+
+	return page, nil
 }
 
 // Функция получения общей стоимости подписок
-func (s *SubscriptionServiceImplementation) GetTotalCost(ctx context.Context, userID, serviceName string, startDate, endDate time.Time) (int, error) {
-	total, err := s.repo.GetTotalCost(ctx, userID, serviceName, startDate, endDate)
+func (s *SubscriptionServiceImplementation) GetTotalCost(ctx context.Context, filter domain.CostReportFilter) (int, error) {
+	filter, err := normalizeCostReportFilter(filter)
 	if err != nil {
 		return 0, err
 	}
 
-	return total, nil
+	charges, err := s.repo.ListCharges(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return totalCost(chargeCosts(charges, filter.StartDate, filter.EndDate, filter.Proration)), nil
+}
+
+// Функция получения стоимости подписок по месяцам и сервисам
+func (s *SubscriptionServiceImplementation) GetCostBreakdown(ctx context.Context, filter domain.CostReportFilter) (domain.CostBreakdown, error) {
+	filter, err := normalizeCostReportFilter(filter)
+	if err != nil {
+		return domain.CostBreakdown{}, err
+	}
+
+	charges, err := s.repo.ListCharges(ctx, filter)
+	if err != nil {
+		return domain.CostBreakdown{}, err
+	}
+
+	costs := chargeCosts(charges, filter.StartDate, filter.EndDate, filter.Proration)
+
+	breakdown := buildCostBreakdown(costs, filter.StartDate, filter.EndDate)
+	breakdown.Currency = filter.Currency
+
+	return breakdown, nil
+}
+
+// Заполнение валюты и режима расчета отчета по умолчанию и их проверка
+func normalizeCostReportFilter(filter domain.CostReportFilter) (domain.CostReportFilter, error) {
+	if filter.Currency == "" {
+		filter.Currency = domain.DefaultCurrency
+	}
+	if !domain.ValidCurrency(filter.Currency) {
+		return domain.CostReportFilter{}, domain.ErrInvalidCurrency
+	}
+	if filter.Proration == "" {
+		filter.Proration = domain.ProrationNone
+	}
+	if !filter.Proration.Valid() {
+		return domain.CostReportFilter{}, domain.ErrInvalidProration
+	}
+
+	return filter, nil
+}
+
+// Сборка матрицы стоимости: строки - сервисы, столбцы - месяцы периода.
+// Месяцы без начислений заполняются нулями, чтобы столбцы были у всех строк одинаковыми.
+// Стоимость округляется в каждой ячейке (roundCost), итоги складываются из округленных ячеек.
+func buildCostBreakdown(costs []domain.MonthlyServiceCost, startDate, endDate time.Time) domain.CostBreakdown {
+	first := time.Date(startDate.Year(), startDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(endDate.Year(), endDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	breakdown := domain.CostBreakdown{
+		Months:      make([]time.Time, 0),
+		Services:    make([]domain.ServiceCostRow, 0),
+		MonthTotals: make([]int, 0),
+	}
+
+	monthIndex := make(map[time.Time]int)
+	for m := first; !m.After(last); m = m.AddDate(0, 1, 0) {
+		monthIndex[m] = len(breakdown.Months)
+		breakdown.Months = append(breakdown.Months, m)
+		breakdown.MonthTotals = append(breakdown.MonthTotals, 0)
+	}
+
+	serviceIndex := make(map[string]int)
+	for _, cost := range costs {
+		month := time.Date(cost.Month.Year(), cost.Month.Month(), 1, 0, 0, 0, 0, time.UTC)
+		col, ok := monthIndex[month]
+		if !ok {
+			continue
+		}
+
+		row, ok := serviceIndex[cost.ServiceName]
+		if !ok {
+			row = len(breakdown.Services)
+			serviceIndex[cost.ServiceName] = row
+			breakdown.Services = append(breakdown.Services, domain.ServiceCostRow{
+				ServiceName: cost.ServiceName,
+				Costs:       make([]int, len(breakdown.Months)),
+			})
+		}
+
+		rounded := roundCost(cost.Cost)
+		breakdown.Services[row].Costs[col] += rounded
+		breakdown.Services[row].Total += rounded
+		breakdown.MonthTotals[col] += rounded
+		breakdown.Total += rounded
+	}
+
+	sort.Slice(breakdown.Services, func(i, j int) bool {
+		return breakdown.Services[i].ServiceName < breakdown.Services[j].ServiceName
+	})
+
+	return breakdown
 }
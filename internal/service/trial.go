@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/levinOo/go-crudl-task/internal/domain"
+)
+
+// Функция завершения пробных периодов во всех организациях.
+//
+// Пробная подписка, у которой закончился пробный период, становится оплачиваемой со дня после trial_end_date.
+// Пробная подписка, срок которой закончился раньше пробного периода, и отмененная подписка
+// после даты окончания завершаются. Каждая смена статуса записывается в историю
+// от имени domain.SystemActor и после фиксации транзакции публикуется в шину событий.
+//
+// Текущий день определяется по часовому поясу контекста. Ошибка одной организации
+// не останавливает обработку остальных. Возвращает число подписок со смененным статусом
+func (s *SubscriptionServiceImplementation) CompleteTrials(ctx context.Context) (int, error) {
+	tenants, err := s.tenants.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		count int
+		errs  []error
+	)
+
+	for _, tenantID := range tenants {
+		n, err := s.completeTrials(domain.WithActor(domain.WithTenant(ctx, tenantID), domain.SystemActor))
+		count += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Ошибка при завершении пробных периодов организации %s: %w", tenantID, err))
+		}
+	}
+
+	return count, errors.Join(errs...)
+}
+
+// Завершение пробных периодов в организации из контекста
+func (s *SubscriptionServiceImplementation) completeTrials(ctx context.Context) (int, error) {
+	today := today(ctx)
+
+	due, err := s.repo.ListStatusDue(ctx, today)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		count int
+		errs  []error
+	)
+
+	for _, sub := range due {
+		event, changed, err := s.completeTrial(ctx, sub.ID, today)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("подписка %s: %w", sub.ID, err))
+			continue
+		}
+		if !changed {
+			continue
+		}
+		count++
+
+		if err := s.publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("Ошибка при публикации события подписки %s: %w", sub.ID, err))
+		}
+	}
+
+	return count, errors.Join(errs...)
+}
+
+// Смена статуса подписки по дате в отдельной транзакции.
+// Подписка перечитывается под блокировкой: с момента выборки ее могли изменить
+func (s *SubscriptionServiceImplementation) completeTrial(ctx context.Context, id string, today time.Time) (domain.SubscriptionEvent, bool, error) {
+	var (
+		event   domain.SubscriptionEvent
+		changed bool
+	)
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := s.repo.Lock(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current.DeletedAt != nil {
+			return nil
+		}
+
+		action, change, ok := dueStatusChange(current, today)
+		if !ok {
+			return nil
+		}
+		change.IfVersion = &current.Version
+
+		updated, err := s.repo.ChangeStatus(ctx, id, change)
+		if err != nil {
+			return err
+		}
+
+		event = newEvent(ctx, action, id, &current, &updated)
+		if err := s.events.Create(ctx, event); err != nil {
+			return err
+		}
+
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return domain.SubscriptionEvent{}, false, err
+	}
+
+	return event, changed, nil
+}
+
+// Смена статуса, которая положена подписке на день today, ok = false - статус не меняется.
+//
+// Срок подписки, закончившийся в пробный период, завершает ее без оплаты.
+// Иначе после пробного периода подписка становится оплачиваемой со следующего дня
+func dueStatusChange(sub domain.Subscription, today time.Time) (domain.SubscriptionAction, domain.StatusChange, bool) {
+	ended := sub.EndDate != nil && sub.EndDate.Before(today)
+
+	switch sub.Status {
+	case domain.StatusTrial:
+		if ended && (sub.TrialEndDate == nil || !sub.EndDate.After(*sub.TrialEndDate)) {
+			return domain.ActionExpired, domain.StatusChange{Status: domain.StatusExpired, At: sub.EndDate.AddDate(0, 0, 1)}, true
+		}
+		if sub.TrialEndDate != nil && sub.TrialEndDate.Before(today) {
+			return domain.ActionActivated, domain.StatusChange{Status: domain.StatusActive, At: sub.TrialEndDate.AddDate(0, 0, 1)}, true
+		}
+	case domain.StatusCancelled:
+		if ended {
+			return domain.ActionExpired, domain.StatusChange{Status: domain.StatusExpired, At: sub.EndDate.AddDate(0, 0, 1)}, true
+		}
+	}
+
+	return "", domain.StatusChange{}, false
+}
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+    ADD COLUMN billing_period VARCHAR(16) NOT NULL DEFAULT 'monthly'
+    CONSTRAINT subscriptions_billing_period_check
+    CHECK (billing_period IN ('weekly', 'monthly', 'quarterly', 'yearly'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS billing_period;
+-- +goose StatementEnd
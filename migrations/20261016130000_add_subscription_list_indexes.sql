@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Индексы под keyset-пагинацию списка подписок пользователя по каждому полю сортировки
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_start_date ON subscriptions(user_id, start_date, id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_price ON subscriptions(user_id, price, id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_service_name ON subscriptions(user_id, service_name, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_subscriptions_user_start_date;
+DROP INDEX IF EXISTS idx_subscriptions_user_price;
+DROP INDEX IF EXISTS idx_subscriptions_user_service_name;
+-- +goose StatementEnd
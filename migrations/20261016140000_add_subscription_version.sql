@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Версия подписки для оптимистичной блокировки, увеличивается при каждом изменении
+ALTER TABLE subscriptions ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+-- История изменений подписок. Внешнего ключа нет: история хранится и после очистки корзины
+CREATE TABLE IF NOT EXISTS subscription_events (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor VARCHAR(255),
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_subscription_events_subscription_id ON subscription_events(subscription_id, id);
+
+-- Для уже существующих подписок сохраняем текущее состояние как событие создания
+INSERT INTO subscription_events (subscription_id, action, after, created_at)
+SELECT
+    id,
+    'created',
+    jsonb_build_object(
+        'id', id,
+        'service_name', service_name,
+        'price', price,
+        'currency', currency,
+        'billing_period', billing_period,
+        'user_id', user_id,
+        'start_date', to_char(start_date, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+        'end_date', to_char(end_date, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+        'created_at', to_char(created_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+        'updated_at', to_char(updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+        'version', version,
+        'deleted_at', to_char(deleted_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+    ),
+    created_at
+FROM subscriptions;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS subscription_events;
+-- +goose StatementEnd
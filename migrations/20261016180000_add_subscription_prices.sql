@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- История цен подписок: каждая цена действует с месяца effective_from до месяца следующей записи.
+-- subscriptions.price хранит цену последней записи
+CREATE TABLE IF NOT EXISTS subscription_prices (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    effective_from TIMESTAMP NOT NULL,
+    price BIGINT NOT NULL,
+    PRIMARY KEY (subscription_id, effective_from)
+);
+
+-- Текущая цена существующих подписок действует с месяца их начала
+INSERT INTO subscription_prices (subscription_id, effective_from, price)
+SELECT id, date_trunc('month', start_date), price
+FROM subscriptions;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS subscription_prices;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- API ключи для межсервисных вызовов. Ключ имеет вид <id>.<secret>,
+-- хранится только SHA-256 от соли и секрета
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id VARCHAR(255) NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    salt BYTEA NOT NULL,
+    secret_hash BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    rotated_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
@@ -0,0 +1,107 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Организации. Существующие данные относятся к организации по умолчанию
+ALTER TABLE subscriptions ADD COLUMN tenant_id VARCHAR(255) NOT NULL DEFAULT 'default';
+ALTER TABLE subscriptions ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE subscription_prices ADD COLUMN tenant_id VARCHAR(255) NOT NULL DEFAULT 'default';
+ALTER TABLE subscription_prices ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE subscription_events ADD COLUMN tenant_id VARCHAR(255) NOT NULL DEFAULT 'default';
+ALTER TABLE subscription_events ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE api_keys ADD COLUMN tenant_id VARCHAR(255) NOT NULL DEFAULT 'default';
+ALTER TABLE api_keys ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- Ключ идемпотентности уникален в пределах организации
+ALTER TABLE idempotency_keys ADD COLUMN tenant_id VARCHAR(255) NOT NULL DEFAULT 'default';
+ALTER TABLE idempotency_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, key);
+
+-- Курсы валют загружает администратор организации
+ALTER TABLE exchange_rates ADD COLUMN tenant_id VARCHAR(255) NOT NULL DEFAULT 'default';
+ALTER TABLE exchange_rates ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE exchange_rates DROP CONSTRAINT exchange_rates_pkey;
+ALTER TABLE exchange_rates ADD PRIMARY KEY (tenant_id, currency);
+
+CREATE INDEX idx_subscriptions_tenant_user_id ON subscriptions(tenant_id, user_id);
+CREATE INDEX idx_subscription_events_tenant_id ON subscription_events(tenant_id);
+CREATE INDEX idx_api_keys_tenant_user_id ON api_keys(tenant_id, user_id);
+
+-- Строки видны только в сессии с app.tenant_id их организации.
+-- FORCE распространяет политики и на владельца таблиц, под которым работает приложение.
+-- api_keys без RLS: ключ ищется по ID до того, как известна организация
+ALTER TABLE subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON subscriptions
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE subscription_prices ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscription_prices FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON subscription_prices
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE subscription_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscription_events FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON subscription_events
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON idempotency_keys
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE exchange_rates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE exchange_rates FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON exchange_rates
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP POLICY IF EXISTS tenant_isolation ON exchange_rates;
+ALTER TABLE exchange_rates NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE exchange_rates DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON idempotency_keys;
+ALTER TABLE idempotency_keys NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON subscription_events;
+ALTER TABLE subscription_events NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE subscription_events DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON subscription_prices;
+ALTER TABLE subscription_prices NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE subscription_prices DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON subscriptions;
+ALTER TABLE subscriptions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE subscriptions DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS idx_api_keys_tenant_user_id;
+DROP INDEX IF EXISTS idx_subscription_events_tenant_id;
+DROP INDEX IF EXISTS idx_subscriptions_tenant_user_id;
+
+DELETE FROM idempotency_keys a USING idempotency_keys b
+WHERE a.key = b.key AND a.tenant_id > b.tenant_id;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN tenant_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
+
+DELETE FROM exchange_rates WHERE tenant_id <> 'default';
+ALTER TABLE exchange_rates DROP CONSTRAINT exchange_rates_pkey;
+ALTER TABLE exchange_rates DROP COLUMN tenant_id;
+ALTER TABLE exchange_rates ADD PRIMARY KEY (currency);
+
+ALTER TABLE api_keys DROP COLUMN tenant_id;
+ALTER TABLE subscription_events DROP COLUMN tenant_id;
+ALTER TABLE subscription_prices DROP COLUMN tenant_id;
+ALTER TABLE subscriptions DROP COLUMN tenant_id;
+-- +goose StatementEnd
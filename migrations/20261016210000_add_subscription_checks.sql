@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ограничения повторяют проверку запросов в API.
+-- NOT VALID: существующие строки не проверяются, ограничения действуют для новых и изменяемых строк.
+-- После исправления старых данных их можно проверить через ALTER TABLE ... VALIDATE CONSTRAINT
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_service_name_check CHECK (service_name <> '') NOT VALID;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_price_check CHECK (price > 0) NOT VALID;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_user_id_check
+    CHECK (user_id ~ '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$') NOT VALID;
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_period_check CHECK (end_date IS NULL OR end_date >= start_date) NOT VALID;
+
+ALTER TABLE subscription_prices
+    ADD CONSTRAINT subscription_prices_price_check CHECK (price > 0) NOT VALID;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE subscription_prices DROP CONSTRAINT IF EXISTS subscription_prices_price_check;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_period_check;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_user_id_check;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_price_check;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_service_name_check;
+-- +goose StatementEnd
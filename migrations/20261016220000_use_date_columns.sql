@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Даты подписок и цен хранятся с точностью до дня.
+-- Раньше даты задавались месяцем: end_date хранилась первым днем месяца и означала весь месяц,
+-- поэтому при переходе она переносится на последний день своего месяца
+ALTER TABLE subscriptions
+    ALTER COLUMN start_date TYPE DATE USING start_date::DATE,
+    ALTER COLUMN end_date TYPE DATE USING (date_trunc('month', end_date) + interval '1 month' - interval '1 day')::DATE;
+
+ALTER TABLE subscription_prices
+    ALTER COLUMN effective_from TYPE DATE USING effective_from::DATE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE subscription_prices
+    ALTER COLUMN effective_from TYPE TIMESTAMP USING effective_from::TIMESTAMP;
+
+ALTER TABLE subscriptions
+    ALTER COLUMN start_date TYPE TIMESTAMP USING start_date::TIMESTAMP,
+    ALTER COLUMN end_date TYPE TIMESTAMP USING date_trunc('month', end_date);
+-- +goose StatementEnd
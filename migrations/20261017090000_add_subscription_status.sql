@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Статус подписки. Существующие подписки считаются активными
+ALTER TABLE subscriptions ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active';
+ALTER TABLE subscriptions ADD CONSTRAINT subscriptions_status_check
+    CHECK (status IN ('trial', 'active', 'paused', 'cancelled', 'expired'));
+
+CREATE INDEX idx_subscriptions_tenant_status ON subscriptions(tenant_id, status);
+
+-- Периоды активности подписок: статус trial, active или paused действует с started_at
+-- до ended_at, не включая его. У текущего периода ended_at не задан.
+-- Отчеты о стоимости не учитывают периоды trial и paused
+CREATE TABLE IF NOT EXISTS subscription_periods (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL CHECK (status IN ('trial', 'active', 'paused')),
+    started_at DATE NOT NULL,
+    ended_at DATE CHECK (ended_at >= started_at)
+);
+
+CREATE INDEX idx_subscription_periods_subscription_id ON subscription_periods(tenant_id, subscription_id, started_at);
+
+-- Существующие подписки активны с даты начала.
+-- Заполняется до включения RLS у новой таблицы, а у subscriptions RLS на время
+-- отключается для владельца: миграция выполняется вне какой-либо организации
+ALTER TABLE subscriptions NO FORCE ROW LEVEL SECURITY;
+INSERT INTO subscription_periods (subscription_id, tenant_id, status, started_at)
+SELECT id, tenant_id, 'active', start_date
+FROM subscriptions;
+ALTER TABLE subscriptions FORCE ROW LEVEL SECURITY;
+
+ALTER TABLE subscription_periods ENABLE ROW LEVEL SECURITY;
+ALTER TABLE subscription_periods FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON subscription_periods
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS subscription_periods;
+
+DROP INDEX IF EXISTS idx_subscriptions_tenant_status;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_status_check;
+ALTER TABLE subscriptions DROP COLUMN status;
+-- +goose StatementEnd
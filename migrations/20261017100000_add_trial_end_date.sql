@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Последний день пробного периода, включительно
+ALTER TABLE subscriptions ADD COLUMN trial_end_date DATE;
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_trial_end_date_check CHECK (trial_end_date IS NULL OR trial_end_date >= start_date);
+
+-- Подписки, статус которых меняет фоновая задача по дате
+CREATE INDEX idx_subscriptions_trial_end_date ON subscriptions(tenant_id, trial_end_date) WHERE status = 'trial';
+CREATE INDEX idx_subscriptions_cancelled_end_date ON subscriptions(tenant_id, end_date) WHERE status = 'cancelled';
+
+-- Организации с подписками. Без RLS: фоновые задачи перебирают организации,
+-- не зная заранее ни одной из них. Таблица хранит только идентификаторы организаций
+CREATE TABLE IF NOT EXISTS tenants (
+    id VARCHAR(255) PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- У subscriptions RLS на время отключается для владельца: миграция выполняется вне какой-либо организации
+ALTER TABLE subscriptions NO FORCE ROW LEVEL SECURITY;
+INSERT INTO tenants (id)
+SELECT DISTINCT tenant_id FROM subscriptions
+ON CONFLICT (id) DO NOTHING;
+ALTER TABLE subscriptions FORCE ROW LEVEL SECURITY;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS tenants;
+
+DROP INDEX IF EXISTS idx_subscriptions_cancelled_end_date;
+DROP INDEX IF EXISTS idx_subscriptions_trial_end_date;
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_trial_end_date_check;
+ALTER TABLE subscriptions DROP COLUMN trial_end_date;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Ключ идемпотентности уникален в пределах пользователя: повтор чужого ключа не получает чужой ответ.
+-- Прежние ключи ни за кем не закреплены и просто истекают
+ALTER TABLE idempotency_keys ADD COLUMN user_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys ALTER COLUMN user_id DROP DEFAULT;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, user_id, key);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- RLS на время отключается для владельца: миграция выполняется вне какой-либо организации
+ALTER TABLE idempotency_keys NO FORCE ROW LEVEL SECURITY;
+DELETE FROM idempotency_keys a USING idempotency_keys b
+WHERE a.tenant_id = b.tenant_id AND a.key = b.key AND a.user_id > b.user_id;
+ALTER TABLE idempotency_keys FORCE ROW LEVEL SECURITY;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN user_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, key);
+-- +goose StatementEnd
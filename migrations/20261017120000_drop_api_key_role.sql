@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Роль владельца известна только из его токена, ключ действует с правами роли user
+ALTER TABLE api_keys DROP COLUMN role;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE api_keys ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user';
+ALTER TABLE api_keys ALTER COLUMN role DROP DEFAULT;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Пользователи сравниваются в нижнем регистре: API приводит к нему user_id и sub токена.
+-- Старые строки приводятся так же, после чего ограничение user_id проверяется для всех строк.
+-- RLS на время отключается для владельца: миграция выполняется вне какой-либо организации
+ALTER TABLE subscriptions NO FORCE ROW LEVEL SECURITY;
+UPDATE subscriptions SET user_id = lower(user_id) WHERE user_id <> lower(user_id);
+ALTER TABLE subscriptions FORCE ROW LEVEL SECURITY;
+
+UPDATE api_keys SET user_id = lower(user_id) WHERE user_id <> lower(user_id);
+
+-- Ключи идемпотентности короткоживущие, при совпадении после приведения они бы конфликтовали
+ALTER TABLE idempotency_keys NO FORCE ROW LEVEL SECURITY;
+DELETE FROM idempotency_keys WHERE user_id <> lower(user_id);
+ALTER TABLE idempotency_keys FORCE ROW LEVEL SECURITY;
+
+ALTER TABLE subscriptions VALIDATE CONSTRAINT subscriptions_user_id_check;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Приведение регистра не отменяется, ограничение снова перестает проверять старые строки
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_user_id_check;
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_user_id_check
+    CHECK (user_id ~ '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$') NOT VALID;
+-- +goose StatementEnd
@@ -0,0 +1,62 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Обработчик события
+type Handler[E any] func(ctx context.Context, event E) error
+
+// Шина событий в памяти процесса.
+//
+// Обработчики вызываются синхронно в порядке подписки в горутине, публикующей событие,
+// поэтому долгую работу (отправку уведомлений и т.п.) обработчик должен выполнять сам в фоне.
+// События не сохраняются: подписчики получают только события, опубликованные после подписки
+type Bus[E any] struct {
+	mu       sync.RWMutex
+	handlers []Handler[E]
+}
+
+// Функция конструктор
+func New[E any]() *Bus[E] {
+	return &Bus[E]{}
+}
+
+// Подписка обработчика на все последующие события
+func (b *Bus[E]) Subscribe(handler Handler[E]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+// Публикация события всем подписчикам.
+// Ошибка или паника одного обработчика не мешает остальным, ошибки всех обработчиков возвращаются вместе
+func (b *Bus[E]) Publish(ctx context.Context, event E) error {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := call(ctx, handler, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Вызов обработчика с перехватом паники
+func call[E any](ctx context.Context, handler Handler[E], event E) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("паника в обработчике события: %v", r)
+		}
+	}()
+
+	return handler(ctx, event)
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Как часто хранилище в памяти удаляет полные ведра
+const sweepInterval = time.Minute
+
+// Ведро вместе с лимитом, по которому оно пополняется
+type memoryBucket struct {
+	bucket
+	limit Limit
+}
+
+// Хранилище ведер в памяти процесса.
+// Подходит для одного экземпляра сервиса: у каждого экземпляра свои счетчики
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// Функция конструктор
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+	}
+}
+
+// Попытка взять один токен из ведра key.
+// Новое ведро создается полным
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{
+			bucket: bucket{tokens: float64(limit.Requests), updated: now},
+		}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+
+	return b.take(limit, now), nil
+}
+
+// Удаление ведер, которые уже наполнились: полное ведро ничем не отличается от отсутствующего
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if b.full(b.limit, now) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Лимит запросов: не больше Requests за Period.
+// Ведро вмещает Requests токенов и равномерно пополняется за Period
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Лимит задан и должен проверяться
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// Скорость пополнения ведра, токенов в секунду
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Результат попытки взять токен
+type Result struct {
+	Allowed    bool          // Запрос разрешен
+	Limit      int           // Емкость ведра
+	Remaining  int           // Сколько запросов еще можно сделать сразу
+	RetryAfter time.Duration // Через сколько появится следующий токен, если запрос отклонен
+	Reset      time.Duration // Через сколько ведро снова наполнится полностью
+}
+
+// Хранилище состояния ведер
+type Store interface {
+	// Попытка взять один токен из ведра key
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Состояние ведра
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Пополнение ведра на момент now и попытка взять из него токен
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	rate := limit.rate()
+	capacity := float64(limit.Requests)
+
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+	}
+	b.updated = now
+
+	result := Result{Limit: limit.Requests}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+
+	return result
+}
+
+// Ведро наполнилось бы полностью к моменту now и его можно не хранить
+func (b *bucket) full(limit Limit, now time.Time) bool {
+	return b.tokens+now.Sub(b.updated).Seconds()*limit.rate() >= float64(limit.Requests)
+}
+
+// Перевод секунд в time.Duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}